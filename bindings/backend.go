@@ -0,0 +1,39 @@
+package bindings
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+)
+
+// ErrReverted is returned when a call or a transaction is reverted
+var ErrReverted = errors.New("execution reverted")
+
+// Backend is the chain used by the generated bindings to send
+// calls and transactions
+type Backend interface {
+	// Call executes the message without persisting any change
+	Call(msg *CallMsg) ([]byte, error)
+
+	// SendTransaction executes the message and persists the changes
+	SendTransaction(msg *CallMsg) (*Receipt, error)
+}
+
+// CallMsg is a message sent to the backend. To is nil for contract creations.
+type CallMsg struct {
+	From  ethgo.Address
+	To    *ethgo.Address
+	Data  []byte
+	Value *big.Int
+	Gas   uint64
+}
+
+// Receipt is the result of a transaction
+type Receipt struct {
+	Success         bool
+	ContractAddress ethgo.Address
+	GasUsed         uint64
+	Logs            []*ethgo.Log
+	ReturnValue     []byte
+}
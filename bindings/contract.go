@@ -0,0 +1,196 @@
+package bindings
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// Contract is the contract instance used by the generated bindings
+type Contract struct {
+	addr    ethgo.Address
+	from    ethgo.Address
+	abi     *abi.ABI
+	backend Backend
+}
+
+// NewContract creates a contract instance at the given address
+func NewContract(addr ethgo.Address, abi *abi.ABI, backend Backend) *Contract {
+	return &Contract{
+		addr:    addr,
+		abi:     abi,
+		backend: backend,
+	}
+}
+
+// Addr returns the address of the contract
+func (c *Contract) Addr() ethgo.Address {
+	return c.addr
+}
+
+// ABI returns the abi of the contract
+func (c *Contract) ABI() *abi.ABI {
+	return c.abi
+}
+
+// SetFrom sets the sender of the calls and transactions
+func (c *Contract) SetFrom(from ethgo.Address) {
+	c.from = from
+}
+
+func (c *Contract) method(sig string) (*abi.Method, error) {
+	method := c.abi.GetMethodBySignature(sig)
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found", sig)
+	}
+	return method, nil
+}
+
+// Call calls the method with the given signature and returns the decoded outputs
+func (c *Contract) Call(sig string, args ...interface{}) (map[string]interface{}, error) {
+	method, err := c.method(sig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := method.Encode(args)
+	if err != nil {
+		return nil, err
+	}
+	msg := &CallMsg{
+		From: c.from,
+		To:   &c.addr,
+		Data: data,
+	}
+	ret, err := c.backend.Call(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(method.Outputs.TupleElems()) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	return method.Decode(ret)
+}
+
+// Txn sends a transaction to the method with the given signature
+func (c *Contract) Txn(sig string, value *big.Int, args ...interface{}) (*Receipt, error) {
+	method, err := c.method(sig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := method.Encode(args)
+	if err != nil {
+		return nil, err
+	}
+	msg := &CallMsg{
+		From:  c.from,
+		To:    &c.addr,
+		Data:  data,
+		Value: value,
+	}
+	return c.backend.SendTransaction(msg)
+}
+
+// Deploy deploys a contract with the creation bytecode bin and the constructor arguments
+func Deploy(backend Backend, from ethgo.Address, contractABI *abi.ABI, bin []byte, value *big.Int, args ...interface{}) (*Contract, *Receipt, error) {
+	data := append([]byte{}, bin...)
+	if contractABI.Constructor != nil {
+		input, err := abi.Encode(args, contractABI.Constructor.Inputs)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, input...)
+	}
+
+	msg := &CallMsg{
+		From:  from,
+		Data:  data,
+		Value: value,
+	}
+	receipt, err := backend.SendTransaction(msg)
+	if err != nil {
+		return nil, receipt, err
+	}
+
+	contract := NewContract(receipt.ContractAddress, contractABI, backend)
+	contract.SetFrom(from)
+	return contract, receipt, nil
+}
+
+// DecodeEvent decodes a log emitted by the event. Unlike abi.Event.ParseLog, it
+// supports indexed values of dynamic types which are returned as their topic hash.
+func DecodeEvent(event *abi.Event, log *ethgo.Log) (map[string]interface{}, error) {
+	if event.Anonymous {
+		return nil, fmt.Errorf("anonymous events cannot be decoded")
+	}
+	if !event.Match(log) {
+		return nil, fmt.Errorf("log does not match the event %s", event.Name)
+	}
+
+	var nonIndexed []*abi.TupleElem
+	for _, arg := range event.Inputs.TupleElems() {
+		if !arg.Indexed {
+			nonIndexed = append(nonIndexed, arg)
+		}
+	}
+
+	var data map[string]interface{}
+	if len(nonIndexed) != 0 {
+		raw, err := abi.NewTupleType(nonIndexed).Decode(log.Data)
+		if err != nil {
+			return nil, err
+		}
+		data = raw.(map[string]interface{})
+	}
+
+	res := map[string]interface{}{}
+	topics := log.Topics[1:]
+	dataIndx := 0
+
+	for indx, arg := range event.Inputs.TupleElems() {
+		// unnamed values are indexed by their position
+		name := arg.Name
+		if name == "" {
+			name = strconv.Itoa(indx)
+		}
+
+		if !arg.Indexed {
+			dataName := arg.Name
+			if dataName == "" {
+				dataName = strconv.Itoa(dataIndx)
+			}
+			res[name] = data[dataName]
+			dataIndx++
+			continue
+		}
+
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("not enough topics in the log")
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		if !IsTopicType(arg.Elem) {
+			res[name] = topic
+			continue
+		}
+		val, err := abi.ParseTopic(arg.Elem, topic)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = val
+	}
+	return res, nil
+}
+
+// IsTopicType returns whether an indexed value of the type can be decoded
+// from its topic. Otherwise, the topic is the hash of the value.
+func IsTopicType(t *abi.Type) bool {
+	switch t.Kind() {
+	case abi.KindBool, abi.KindInt, abi.KindUInt, abi.KindAddress:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package bindings
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// defaultGas is the gas limit of the messages without gas
+const defaultGas = 10000000
+
+// SimulatedBackend is a backend that runs the messages in the
+// greenhouse in-memory runtime. It is meant to be used in unit tests.
+type SimulatedBackend struct {
+	transition *state.Transition
+}
+
+// NewSimulatedBackend creates an empty simulated chain
+func NewSimulatedBackend() *SimulatedBackend {
+	return &SimulatedBackend{
+		transition: state.NewTransition(state.WithRevision(evmc.Istanbul)),
+	}
+}
+
+// Fund sets the balance of the account
+func (s *SimulatedBackend) Fund(addr ethgo.Address, amount *big.Int) {
+	s.transition.Txn().SetBalance(evmc.Address(addr), amount)
+}
+
+// Balance returns the balance of the account
+func (s *SimulatedBackend) Balance(addr ethgo.Address) *big.Int {
+	return new(big.Int).Set(s.transition.Txn().GetBalance(evmc.Address(addr)))
+}
+
+func (s *SimulatedBackend) toMessage(msg *CallMsg) *state.Message {
+	value := msg.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	gas := msg.Gas
+	if gas == 0 {
+		gas = defaultGas
+	}
+	from := evmc.Address(msg.From)
+
+	m := &state.Message{
+		From:     from,
+		Nonce:    s.transition.Txn().GetNonce(from),
+		GasPrice: big.NewInt(0),
+		Gas:      gas,
+		Value:    value,
+		Input:    msg.Data,
+	}
+	if msg.To != nil {
+		to := evmc.Address(*msg.To)
+		m.To = &to
+	}
+	return m
+}
+
+// Call implements the Backend interface
+func (s *SimulatedBackend) Call(msg *CallMsg) ([]byte, error) {
+	txn := s.transition.Txn()
+
+	// discard any change done by the call
+	snapshot := txn.Snapshot()
+	output := s.transition.Apply(s.toMessage(msg))
+	if err := txn.RevertToSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	if !output.Success {
+		return nil, revertError(output.ReturnValue)
+	}
+	return output.ReturnValue, nil
+}
+
+// SendTransaction implements the Backend interface
+func (s *SimulatedBackend) SendTransaction(msg *CallMsg) (*Receipt, error) {
+	m := s.toMessage(msg)
+	gas := m.Gas
+
+	output, err := s.transition.Write(m)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &Receipt{
+		Success:     output.Success,
+		GasUsed:     gas - output.GasLeft,
+		ReturnValue: output.ReturnValue,
+	}
+	if msg.To == nil {
+		receipt.ContractAddress = ethgo.Address(output.ContractAddress)
+	}
+	for _, log := range output.Logs {
+		topics := []ethgo.Hash{}
+		for _, topic := range log.Topics {
+			topics = append(topics, ethgo.Hash(topic))
+		}
+		receipt.Logs = append(receipt.Logs, &ethgo.Log{
+			Address: ethgo.Address(log.Address),
+			Topics:  topics,
+			Data:    log.Data,
+		})
+	}
+	if !output.Success {
+		return receipt, revertError(output.ReturnValue)
+	}
+	return receipt, nil
+}
+
+func revertError(data []byte) error {
+	if reason, err := abi.UnpackRevertError(data); err == nil {
+		return fmt.Errorf("%w: %s", ErrReverted, reason)
+	}
+	return ErrReverted
+}
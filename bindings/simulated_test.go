@@ -0,0 +1,31 @@
+package bindings
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+func TestSimulatedBackend_DeployAndCall(t *testing.T) {
+	// contract that returns 42 for any call
+	bin, err := hex.DecodeString("600a600c600039600a6000f3" + "602a60005260206000f3")
+	assert.NoError(t, err)
+
+	contractABI := abi.MustNewABI(`[{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`)
+
+	backend := NewSimulatedBackend()
+	from := ethgo.Address{0x1}
+
+	contract, receipt, err := Deploy(backend, from, contractABI, bin, nil)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Success)
+	assert.NotEqual(t, ethgo.Address{}, contract.Addr())
+
+	res, err := contract.Call("get()")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), res["0"])
+}
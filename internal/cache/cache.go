@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entrySuffix is the suffix of the files that store the cache entries
+const entrySuffix = ".json"
+
+// Cache is a content addressed store on disk. Entries are evicted
+// in least recently used order once the size of the cache exceeds its limit.
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+// NewCache creates a cache in dir with a limit of maxSize bytes.
+// A maxSize of zero disables the eviction.
+func NewCache(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	c := &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+	return c, nil
+}
+
+// Key returns the content address of the given parts
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// prefix each part with its length so that the boundaries are part of the hash
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+entrySuffix)
+}
+
+// Get returns the entry stored for the key
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	path := c.path(key)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	// update the modification time to track the last use of the entry
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put stores the entry for the key and evicts old entries if
+// the cache is over its limit
+func (c *Cache) Put(key string, data []byte) error {
+	tmp, err := ioutil.TempFile(c.dir, key+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+
+	if c.maxSize != 0 {
+		if _, err := c.Prune(c.maxSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type entry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *Cache) entries() ([]*entry, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*entry{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), entrySuffix) {
+			continue
+		}
+		entries = append(entries, &entry{
+			path:    filepath.Join(c.dir, file.Name()),
+			size:    file.Size(),
+			modTime: file.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// Stats is the usage of the cache
+type Stats struct {
+	Dir     string
+	Entries int
+	Size    int64
+	MaxSize int64
+}
+
+// Stats returns the usage of the cache
+func (c *Cache) Stats() (*Stats, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{
+		Dir:     c.dir,
+		Entries: len(entries),
+		MaxSize: c.maxSize,
+	}
+	for _, e := range entries {
+		stats.Size += e.size
+	}
+	return stats, nil
+}
+
+// Prune removes the least recently used entries until the size of the
+// cache is at most maxSize bytes. It returns the number of removed entries.
+func (c *Cache) Prune(maxSize int64) (int, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(0)
+	for _, e := range entries {
+		size += e.size
+	}
+
+	// remove first the oldest entries
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	removed := 0
+	for _, e := range entries {
+		if size <= maxSize {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		size -= e.size
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Key(t *testing.T) {
+	assert.Equal(t, Key([]byte("a"), []byte("b")), Key([]byte("a"), []byte("b")))
+	assert.NotEqual(t, Key([]byte("ab"), []byte("")), Key([]byte("a"), []byte("b")))
+}
+
+func TestCache_GetPut(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	c, err := NewCache(tmpDir, 0)
+	assert.NoError(t, err)
+
+	_, ok, err := c.Get("a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Put("a", []byte("data")))
+
+	data, ok, err := c.Get("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("data"), data)
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, int64(4), stats.Size)
+}
+
+func TestCache_Eviction(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	c, err := NewCache(tmpDir, 8)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Put("a", []byte("1234")))
+	assert.NoError(t, c.Put("b", []byte("1234")))
+
+	// make 'a' the oldest entry and 'b' the most recently used
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(c.path("a"), past, past))
+
+	assert.NoError(t, c.Put("c", []byte("1234")))
+
+	_, ok, _ := c.Get("a")
+	assert.False(t, ok)
+	_, ok, _ = c.Get("b")
+	assert.True(t, ok)
+	_, ok, _ = c.Get("c")
+	assert.True(t, ok)
+
+	removed, err := c.Prune(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+}
@@ -1,15 +1,30 @@
 package cli
 
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	flag "github.com/spf13/pflag"
+	"github.com/umbracle/greenhouse/internal/core"
+)
+
 // BuildCommand is the command to show the version of the agent
 type BuildCommand struct {
 	*baseCommand
+
+	format      string
+	sizes       bool
+	strictSizes bool
 }
 
 // Help implements the cli.Command interface
 func (b *BuildCommand) Help() string {
 	return `Usage: greenhouse build
 
-  Build and compile the project`
+  Build and compile the project
+
+` + b.Flags().FlagUsages()
 }
 
 // Synopsis implements the cli.Command interface
@@ -17,23 +32,102 @@ func (b *BuildCommand) Synopsis() string {
 	return "Build and compile the project"
 }
 
+func (b *BuildCommand) Flags() *flag.FlagSet {
+	flags := b.baseCommand.Flags("build")
+
+	flags.StringVar(&b.format, "format", formatText, "Output format (text or json)")
+	flags.BoolVar(&b.sizes, "sizes", false, "Print the code sizes of the contracts")
+	flags.BoolVar(&b.strictSizes, "strict-sizes", false, "Fail the build if a contract is over the code size limits")
+
+	return flags
+}
+
 // Run implements the cli.Command interface
 func (b *BuildCommand) Run(args []string) int {
-	flags := b.Flags("build")
+	flags := b.Flags()
 	if err := flags.Parse(args); err != nil {
 		b.UI.Error(err.Error())
 		return 1
 	}
+	if err := validateFormat(b.format); err != nil {
+		b.UI.Error(err.Error())
+		return 1
+	}
 
 	if err := b.Init(); err != nil {
-		b.UI.Error(err.Error())
+		b.error(b.format, err)
 		return 1
 	}
-	if err := b.project.Compile(); err != nil {
-		b.UI.Error(err.Error())
+	result, err := b.project.Compile()
+	if err != nil {
+		if b.format == formatJSON {
+			b.emit(&buildEvent{Type: "build", Success: false, Error: err.Error()})
+		} else {
+			b.UI.Error(err.Error())
+		}
+		return 1
+	}
+
+	sizes, err := b.project.Sizes()
+	if err != nil {
+		b.error(b.format, err)
 		return 1
 	}
+	oversized := []string{}
+	for _, size := range sizes {
+		if size.Oversized() {
+			oversized = append(oversized, fmt.Sprintf("contract %s (%s) is over the code size limits: runtime %d/%d bytes, initcode %d/%d bytes", size.Name, size.Source, size.Runtime, core.MaxCodeSize, size.Initcode, core.MaxInitCodeSize))
+		}
+	}
+	var sizeErr error
+	if b.strictSizes && len(oversized) != 0 {
+		sizeErr = fmt.Errorf("%d contracts are over the code size limits", len(oversized))
+	}
 
+	if b.format == formatJSON {
+		b.emitContracts(result)
+		for _, msg := range oversized {
+			b.emit(&diagnosticEvent{Type: "diagnostic", Message: msg})
+		}
+		if b.sizes {
+			for _, size := range sizes {
+				b.emitSize(size)
+			}
+		}
+		event := &buildEvent{Type: "build", Success: sizeErr == nil, Contracts: len(result.Contracts)}
+		if sizeErr != nil {
+			event.Error = sizeErr.Error()
+			b.emit(event)
+			return 1
+		}
+		b.emit(event)
+		return 0
+	}
+
+	for _, msg := range result.Diagnostics {
+		b.UI.Warn(msg)
+	}
+	if b.sizes {
+		b.UI.Output(formatSizes(sizes))
+	}
+	for _, msg := range oversized {
+		b.UI.Warn(msg)
+	}
+	if sizeErr != nil {
+		b.UI.Error(sizeErr.Error())
+		return 1
+	}
 	b.UI.Output("Compiled.")
 	return 0
 }
+
+func formatSizes(sizes []*core.ContractSize) string {
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Contract\tRuntime (B)\tMargin (B)\tInitcode (B)\tMargin (B)\t")
+	for _, size := range sizes {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t\n", size.Name, size.Runtime, size.RuntimeMargin(), size.Initcode, size.InitcodeMargin())
+	}
+	w.Flush()
+	return strings.TrimRight(buf.String(), "\n")
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	flag "github.com/spf13/pflag"
+	"github.com/umbracle/greenhouse/internal/core"
+)
+
+// CacheCommand is the command to manage the compilation cache
+type CacheCommand struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *CacheCommand) Help() string {
+	return `Usage: greenhouse cache <subcommand>
+
+  Manage the compilation cache shared by all the projects`
+}
+
+// Synopsis implements the cli.Command interface
+func (c *CacheCommand) Synopsis() string {
+	return "Manage the compilation cache"
+}
+
+// Run implements the cli.Command interface
+func (c *CacheCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// CacheStatsCommand is the command to show the usage of the compilation cache
+type CacheStatsCommand struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *CacheStatsCommand) Help() string {
+	return `Usage: greenhouse cache stats
+
+  Show the usage of the compilation cache`
+}
+
+// Synopsis implements the cli.Command interface
+func (c *CacheStatsCommand) Synopsis() string {
+	return "Show the usage of the compilation cache"
+}
+
+// Run implements the cli.Command interface
+func (c *CacheStatsCommand) Run(args []string) int {
+	cache, err := core.NewCompileCache()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	stats, err := cache.Stats()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Output(fmt.Sprintf("Directory: %s", stats.Dir))
+	c.UI.Output(fmt.Sprintf("Entries:   %d", stats.Entries))
+	c.UI.Output(fmt.Sprintf("Size:      %d/%d bytes", stats.Size, stats.MaxSize))
+	return 0
+}
+
+// CachePruneCommand is the command to remove entries from the compilation cache
+type CachePruneCommand struct {
+	UI cli.Ui
+
+	maxSize int64
+}
+
+// Help implements the cli.Command interface
+func (c *CachePruneCommand) Help() string {
+	return `Usage: greenhouse cache prune
+
+  Remove the least recently used entries of the compilation cache
+
+` + c.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (c *CachePruneCommand) Synopsis() string {
+	return "Remove entries from the compilation cache"
+}
+
+func (c *CachePruneCommand) Flags() *flag.FlagSet {
+	flags := flag.NewFlagSet("cache prune", 0)
+
+	flags.Int64Var(&c.maxSize, "max-size", 0, "Maximum size in bytes of the cache after pruning")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (c *CachePruneCommand) Run(args []string) int {
+	if err := c.Flags().Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	cache, err := core.NewCompileCache()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	removed, err := cache.Prune(c.maxSize)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Output(fmt.Sprintf("Removed %d entries", removed))
+	return 0
+}
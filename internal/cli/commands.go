@@ -27,11 +27,66 @@ func Commands() map[string]cli.CommandFactory {
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"cache": func() (cli.Command, error) {
+			return &CacheCommand{
+				UI: ui,
+			}, nil
+		},
+		"cache stats": func() (cli.Command, error) {
+			return &CacheStatsCommand{
+				UI: ui,
+			}, nil
+		},
+		"cache prune": func() (cli.Command, error) {
+			return &CachePruneCommand{
+				UI: ui,
+			}, nil
+		},
 		"clean": func() (cli.Command, error) {
 			return &CleanCommand{
 				baseCommand: baseCommand,
 			}, nil
 		},
+		"doctor": func() (cli.Command, error) {
+			return &DoctorCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"flatten": func() (cli.Command, error) {
+			return &FlattenCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"gen": func() (cli.Command, error) {
+			return &GenCommand{
+				UI: ui,
+			}, nil
+		},
+		"gen go": func() (cli.Command, error) {
+			return &GenGoCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"gen interface": func() (cli.Command, error) {
+			return &GenInterfaceCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"gen ts": func() (cli.Command, error) {
+			return &GenTsCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"inspect": func() (cli.Command, error) {
+			return &InspectCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
+		"inspect storage": func() (cli.Command, error) {
+			return &InspectStorageCommand{
+				baseCommand: baseCommand,
+			}, nil
+		},
 		"test": func() (cli.Command, error) {
 			return &TestCommand{
 				baseCommand: baseCommand,
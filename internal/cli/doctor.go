@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+	"github.com/umbracle/greenhouse/internal/core"
+)
+
+// DoctorCommand is the command to check the health of the project
+type DoctorCommand struct {
+	*baseCommand
+}
+
+// Help implements the cli.Command interface
+func (d *DoctorCommand) Help() string {
+	return `Usage: greenhouse doctor
+
+  Check the health of the project`
+}
+
+// Synopsis implements the cli.Command interface
+func (d *DoctorCommand) Synopsis() string {
+	return "Check the health of the project"
+}
+
+func (d *DoctorCommand) Flags() *flag.FlagSet {
+	flags := d.baseCommand.Flags("doctor")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (d *DoctorCommand) Run(args []string) int {
+	if err := d.Flags().Parse(args); err != nil {
+		d.UI.Error(err.Error())
+		return 1
+	}
+
+	healthy := true
+
+	// config file
+	if _, err := os.Stat(defaultConfigFileName); errors.Is(err, os.ErrNotExist) {
+		d.UI.Error(fmt.Sprintf("config: %s not found", defaultConfigFileName))
+		healthy = false
+	} else if _, err := core.LoadConfig(defaultConfigFileName); err != nil {
+		d.UI.Error(fmt.Sprintf("config: %v", err))
+		healthy = false
+	} else {
+		d.UI.Output("config: ok")
+	}
+
+	// metadata store
+	health := core.CheckMetadata(core.MetadataPath)
+	switch health.Status {
+	case core.MetadataOk:
+		d.UI.Output(fmt.Sprintf("metadata: ok (version %d, %d sources, %d contracts)", health.Version, health.Sources, health.Contracts))
+	case core.MetadataMissing:
+		d.UI.Output("metadata: missing (the project has not been built yet)")
+	case core.MetadataOutdated:
+		d.UI.Warn(fmt.Sprintf("metadata: outdated version %d, it will be migrated on the next build", health.Version))
+	default:
+		d.UI.Error(fmt.Sprintf("metadata: %s (%v), the project will be rebuilt on the next build", health.Status, health.Err))
+		healthy = false
+	}
+
+	if !healthy {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlattenCommand is the command to flatten a source file
+type FlattenCommand struct {
+	*baseCommand
+
+	output string
+}
+
+// Help implements the cli.Command interface
+func (f *FlattenCommand) Help() string {
+	return `Usage: greenhouse flatten <file>
+
+  Write a source file and all its imports as a single file. The imports
+  are written before the files that use them and the import statements,
+  license identifiers and repeated pragmas are removed.
+
+` + f.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (f *FlattenCommand) Synopsis() string {
+	return "Flatten a source file and its imports"
+}
+
+func (f *FlattenCommand) Flags() *flag.FlagSet {
+	flags := f.baseCommand.Flags("flatten")
+
+	flags.StringVar(&f.output, "output", "", "File to write the flattened source (default to stdout)")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (f *FlattenCommand) Run(args []string) int {
+	flags := f.Flags()
+	if err := flags.Parse(args); err != nil {
+		f.UI.Error(err.Error())
+		return 1
+	}
+	args = flags.Args()
+	if len(args) != 1 {
+		f.UI.Error("expected one argument: <file>")
+		return 1
+	}
+
+	if err := f.Init(); err != nil {
+		f.UI.Error(err.Error())
+		return 1
+	}
+	source, err := f.project.Flatten(args[0])
+	if err != nil {
+		f.UI.Error(err.Error())
+		return 1
+	}
+
+	if f.output == "" {
+		f.UI.Output(strings.TrimSuffix(source, "\n"))
+		return 0
+	}
+	if err := ioutil.WriteFile(f.output, []byte(source), 0644); err != nil {
+		f.UI.Error(err.Error())
+		return 1
+	}
+	f.UI.Output(fmt.Sprintf("Flattened %s in %s", args[0], f.output))
+	return 0
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/umbracle/greenhouse/internal/core"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+func validateFormat(format string) error {
+	if format != formatText && format != formatJSON {
+		return fmt.Errorf("format '%s' not supported, use '%s' or '%s'", format, formatText, formatJSON)
+	}
+	return nil
+}
+
+// The events below are written as JSON lines (one object per line) to stdout
+// with the --format json flag. Every event has a 'type' field. The schema is
+// documented in the README and fields are only added, never renamed or removed.
+
+// diagnosticEvent is a warning reported by the compiler
+type diagnosticEvent struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// contractEvent is a contract compiled during the build
+type contractEvent struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Artifact string `json:"artifact"`
+}
+
+// buildEvent is the result of the build
+type buildEvent struct {
+	Type      string `json:"type"`
+	Success   bool   `json:"success"`
+	Contracts int    `json:"contracts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// sizeEvent is the code size of a contract in bytes
+type sizeEvent struct {
+	Type           string `json:"type"`
+	Name           string `json:"name"`
+	Source         string `json:"source"`
+	Runtime        int    `json:"runtime"`
+	Initcode       int    `json:"initcode"`
+	RuntimeMargin  int    `json:"runtime_margin"`
+	InitcodeMargin int    `json:"initcode_margin"`
+}
+
+// testEvent is the result of a single test method
+type testEvent struct {
+	Type     string   `json:"type"`
+	Source   string   `json:"source"`
+	Contract string   `json:"contract"`
+	Method   string   `json:"method"`
+	Status   string   `json:"status"`
+	Gas      uint64   `json:"gas"`
+	Duration int64    `json:"duration_us"`
+	Console  []string `json:"console"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+// summaryEvent is the result of the whole test run
+type summaryEvent struct {
+	Type   string `json:"type"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// errorEvent is an error that stopped the command
+type errorEvent struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+func (b *baseCommand) emit(obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		panic(fmt.Errorf("BUG: failed to encode event: %v", err))
+	}
+	b.UI.Output(string(data))
+}
+
+// error reports an error in the given format
+func (b *baseCommand) error(format string, err error) {
+	if format == formatJSON {
+		b.emit(&errorEvent{Type: "error", Error: err.Error()})
+	} else {
+		b.UI.Error(err.Error())
+	}
+}
+
+func (b *baseCommand) emitBuild(result *core.CompileResult) {
+	b.emitContracts(result)
+	b.emit(&buildEvent{Type: "build", Success: true, Contracts: len(result.Contracts)})
+}
+
+// emitContracts emits the diagnostics and the contracts of the build
+func (b *baseCommand) emitContracts(result *core.CompileResult) {
+	for _, msg := range result.Diagnostics {
+		b.emit(&diagnosticEvent{Type: "diagnostic", Message: msg})
+	}
+
+	names := []string{}
+	for name := range result.Contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		contract := result.Contracts[name]
+		b.emit(&contractEvent{
+			Type:     "contract",
+			Name:     contract.Name,
+			Source:   contract.Dir + "/" + contract.Filename,
+			Artifact: result.Artifacts[name],
+		})
+	}
+}
+
+func (b *baseCommand) emitSize(size *core.ContractSize) {
+	b.emit(&sizeEvent{
+		Type:           "size",
+		Name:           size.Name,
+		Source:         size.Source,
+		Runtime:        size.Runtime,
+		Initcode:       size.Initcode,
+		RuntimeMargin:  size.RuntimeMargin(),
+		InitcodeMargin: size.InitcodeMargin(),
+	})
+}
+
+func (b *baseCommand) emitTest(o *core.TestOutput) {
+	status := "pass"
+	if !o.Success {
+		status = "fail"
+	}
+	console := []string{}
+	for _, c := range o.Console {
+		console = append(console, formatConsole(c))
+	}
+	b.emit(&testEvent{
+		Type:     "test",
+		Source:   o.Source,
+		Contract: o.Contract,
+		Method:   o.Method,
+		Status:   status,
+		Gas:      o.GasUsed,
+		Duration: o.Duration.Microseconds(),
+		Console:  console,
+		Reason:   o.Reason,
+	})
+}
+
+func formatConsole(c *core.ConsoleOutput) string {
+	if c.Err != nil {
+		return "error: " + c.Err.Error()
+	}
+	return strings.Join(c.Val, ", ")
+}
@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/mitchellh/cli"
+	flag "github.com/spf13/pflag"
+	"github.com/umbracle/greenhouse/internal/gen"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+// GenCommand is the command to generate code from the compiled contracts
+type GenCommand struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (g *GenCommand) Help() string {
+	return `Usage: greenhouse gen <subcommand>
+
+  Generate code from the compiled contracts`
+}
+
+// Synopsis implements the cli.Command interface
+func (g *GenCommand) Synopsis() string {
+	return "Generate code from the compiled contracts"
+}
+
+// Run implements the cli.Command interface
+func (g *GenCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// GenGoCommand is the command to generate Go bindings
+type GenGoCommand struct {
+	*baseCommand
+
+	pkg    string
+	output string
+}
+
+// Help implements the cli.Command interface
+func (g *GenGoCommand) Help() string {
+	return `Usage: greenhouse gen go --pkg <name>
+
+  Generate Go bindings for the contracts of the project
+
+` + g.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (g *GenGoCommand) Synopsis() string {
+	return "Generate Go bindings"
+}
+
+func (g *GenGoCommand) Flags() *flag.FlagSet {
+	flags := g.baseCommand.Flags("gen go")
+
+	flags.StringVar(&g.pkg, "pkg", "", "Name of the Go package")
+	flags.StringVar(&g.output, "output", "", "Output directory (default to the name of the package)")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (g *GenGoCommand) Run(args []string) int {
+	flags := g.Flags()
+	if err := flags.Parse(args); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	if g.pkg == "" {
+		g.UI.Error("--pkg is required")
+		return 1
+	}
+	if g.output == "" {
+		g.output = g.pkg
+	}
+
+	if err := g.Init(); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	if _, err := g.project.Compile(); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	contracts, err := g.project.Contracts()
+	if err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := checkOutputNames(contracts, goFileName); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	if err := os.MkdirAll(g.output, 0755); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	for _, contract := range contracts {
+		code, err := gen.GenerateGo(g.pkg, contract)
+		if err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		path := filepath.Join(g.output, goFileName(contract))
+		if err := ioutil.WriteFile(path, code, 0644); err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+	}
+	g.UI.Output(fmt.Sprintf("Generated bindings for %d contracts in %s", len(contracts), g.output))
+	return 0
+}
+
+// goFileName returns the name of the file with the Go bindings of the contract
+func goFileName(contract *state.Contract) string {
+	return strings.ToLower(contract.Name) + ".go"
+}
+
+// GenTsCommand is the command to generate TypeScript bindings
+type GenTsCommand struct {
+	*baseCommand
+
+	output string
+	force  bool
+}
+
+// Help implements the cli.Command interface
+func (g *GenTsCommand) Help() string {
+	return `Usage: greenhouse gen ts
+
+  Generate TypeScript modules for the contracts of the project.
+  A module is only generated again if the abi or the bytecode of the
+  contract changes.
+
+` + g.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (g *GenTsCommand) Synopsis() string {
+	return "Generate TypeScript bindings"
+}
+
+func (g *GenTsCommand) Flags() *flag.FlagSet {
+	flags := g.baseCommand.Flags("gen ts")
+
+	flags.StringVar(&g.output, "output", "bindings", "Output directory")
+	flags.BoolVar(&g.force, "force", false, "Generate the modules even if the contracts did not change")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (g *GenTsCommand) Run(args []string) int {
+	flags := g.Flags()
+	if err := flags.Parse(args); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := g.Init(); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	if _, err := g.project.Compile(); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	contracts, err := g.project.Contracts()
+	if err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := checkOutputNames(contracts, gen.TypeScriptModule); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	if err := os.MkdirAll(g.output, 0755); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	generated := 0
+	for _, contract := range contracts {
+		path := filepath.Join(g.output, gen.TypeScriptModule(contract)+".ts")
+
+		if !g.force {
+			hash, err := gen.TypeScriptHash(contract)
+			if err != nil {
+				g.UI.Error(err.Error())
+				return 1
+			}
+			if data, err := ioutil.ReadFile(path); err == nil && gen.ReadTypeScriptHash(data) == hash {
+				continue
+			}
+		}
+
+		code, err := gen.GenerateTypeScript(contract)
+		if err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		if err := ioutil.WriteFile(path, code, 0644); err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		generated++
+	}
+
+	// the modules of the contracts that no longer exist are not in the index
+	removed, err := removeStaleModules(g.output, contracts)
+	if err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	// the index only changes if the list of contracts does
+	index := gen.GenerateTypeScriptIndex(contracts)
+	indexPath := filepath.Join(g.output, "index.ts")
+	if data, err := ioutil.ReadFile(indexPath); err != nil || !bytes.Equal(data, index) {
+		if err := ioutil.WriteFile(indexPath, index, 0644); err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	msg := fmt.Sprintf("Generated %d of %d modules in %s", generated, len(contracts), g.output)
+	if removed != 0 {
+		msg += fmt.Sprintf(", removed %d stale modules", removed)
+	}
+	g.UI.Output(msg)
+	return 0
+}
+
+// GenInterfaceCommand is the command to generate Solidity interfaces
+type GenInterfaceCommand struct {
+	*baseCommand
+
+	name string
+}
+
+// Help implements the cli.Command interface
+func (g *GenInterfaceCommand) Help() string {
+	return `Usage: greenhouse gen interface <Contract|abi.json>
+
+  Generate a Solidity interface from a contract of the project or an abi file.
+  The interface is written in the interfaces folder of the project.
+
+` + g.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (g *GenInterfaceCommand) Synopsis() string {
+	return "Generate Solidity interfaces"
+}
+
+func (g *GenInterfaceCommand) Flags() *flag.FlagSet {
+	flags := g.baseCommand.Flags("gen interface")
+
+	flags.StringVar(&g.name, "name", "", "Name of the interface (default to I<Contract>)")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (g *GenInterfaceCommand) Run(args []string) int {
+	flags := g.Flags()
+	if err := flags.Parse(args); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	args = flags.Args()
+	if len(args) != 1 {
+		g.UI.Error("expected one argument: <Contract|abi.json>")
+		return 1
+	}
+	target := args[0]
+
+	if err := g.Init(); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	var name, abiStr string
+	if strings.HasSuffix(target, ".json") || strings.HasSuffix(target, ".abi") {
+		data, err := ioutil.ReadFile(target)
+		if err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		if abiStr, err = readABIFile(data); err != nil {
+			g.UI.Error(fmt.Sprintf("failed to read %s: %v", target, err))
+			return 1
+		}
+		name = filepath.Base(target)
+		name = name[:strings.Index(name, ".")]
+	} else {
+		if _, err := g.project.Compile(); err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		contract, err := g.project.Contract(target)
+		if err != nil {
+			g.UI.Error(err.Error())
+			return 1
+		}
+		name, abiStr = contract.Name, contract.Abi
+	}
+
+	if g.name != "" {
+		name = g.name
+	} else if !isInterfaceName(name) {
+		name = "I" + name
+	}
+
+	code, err := gen.GenerateInterface(name, abiStr, g.project.Config().Solidity)
+	if err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+
+	dir := g.project.Config().InterfacesDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	path := filepath.Join(dir, name+".sol")
+	if err := ioutil.WriteFile(path, code, 0644); err != nil {
+		g.UI.Error(err.Error())
+		return 1
+	}
+	g.UI.Output(fmt.Sprintf("Generated interface %s in %s", name, path))
+	return 0
+}
+
+// isInterfaceName returns whether the name follows the IName convention
+func isInterfaceName(name string) bool {
+	return len(name) > 1 && name[0] == 'I' && unicode.IsUpper(rune(name[1]))
+}
+
+// readABIFile returns the abi of either a json abi file or an artifact
+// with an abi field
+func readABIFile(data []byte) (string, error) {
+	var artifact struct {
+		Abi json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(data, &artifact); err == nil {
+		if len(artifact.Abi) == 0 {
+			return "", fmt.Errorf("abi field not found")
+		}
+		return string(artifact.Abi), nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkOutputNames returns an error if two contracts are generated in the
+// same output file. The names are compared without case since the file
+// systems of macOS and Windows are case insensitive.
+func checkOutputNames(contracts []*state.Contract, name func(*state.Contract) string) error {
+	used := map[string]*state.Contract{}
+	for _, contract := range contracts {
+		output := name(contract)
+		if found, ok := used[strings.ToLower(output)]; ok {
+			return fmt.Errorf("contracts '%s' in '%s' and '%s' in '%s' generate the same output '%s'",
+				found.Name, filepath.Join(found.Dir, found.Filename), contract.Name, filepath.Join(contract.Dir, contract.Filename), output)
+		}
+		used[strings.ToLower(output)] = contract
+	}
+	return nil
+}
+
+// removeStaleModules removes the generated TypeScript modules in the
+// folder that do not belong to any of the contracts
+func removeStaleModules(dir string, contracts []*state.Contract) (int, error) {
+	modules := map[string]struct{}{}
+	for _, contract := range contracts {
+		modules[gen.TypeScriptModule(contract)+".ts"] = struct{}{}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".ts") || name == "index.ts" {
+			continue
+		}
+		if _, ok := modules[name]; ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		// only the modules generated by greenhouse have the hash header
+		if gen.ReadTypeScriptHash(data) == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return 0, err
+		}
+		removed++
+	}
+	return removed, nil
+}
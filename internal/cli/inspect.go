@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitchellh/cli"
+	flag "github.com/spf13/pflag"
+	"github.com/umbracle/greenhouse/internal/core"
+)
+
+// inspect views
+const (
+	viewAll         = "all"
+	viewSelectors   = "selectors"
+	viewEvents      = "events"
+	viewErrors      = "errors"
+	viewConstructor = "constructor"
+	viewCompiler    = "compiler"
+	viewBytecode    = "bytecode"
+)
+
+var inspectViews = []string{viewAll, viewSelectors, viewEvents, viewErrors, viewConstructor, viewCompiler, viewBytecode}
+
+// InspectCommand is the command to inspect the compiled contracts
+type InspectCommand struct {
+	*baseCommand
+
+	view   string
+	format string
+}
+
+// Help implements the cli.Command interface
+func (i *InspectCommand) Help() string {
+	return `Usage: greenhouse inspect <Contract>
+
+  Show the selectors, events, errors, constructor, compiler
+  and bytecode hashes of a compiled contract. It reads the
+  last build of the project and does not compile.
+
+` + i.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (i *InspectCommand) Synopsis() string {
+	return "Inspect the compiled contracts"
+}
+
+func (i *InspectCommand) Flags() *flag.FlagSet {
+	flags := i.baseCommand.Flags("inspect")
+
+	flags.StringVar(&i.view, "view", viewAll, "View to show ("+strings.Join(inspectViews, ", ")+")")
+	flags.StringVar(&i.format, "format", formatText, "Output format (text or json)")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (i *InspectCommand) Run(args []string) int {
+	flags := i.Flags()
+	if err := flags.Parse(args); err != nil {
+		i.UI.Error(err.Error())
+		return 1
+	}
+	if err := validateFormat(i.format); err != nil {
+		i.UI.Error(err.Error())
+		return 1
+	}
+	found := false
+	for _, view := range inspectViews {
+		if view == i.view {
+			found = true
+		}
+	}
+	if !found {
+		i.UI.Error(fmt.Sprintf("view '%s' not found, use one of: %s", i.view, strings.Join(inspectViews, ", ")))
+		return 1
+	}
+	args = flags.Args()
+	if len(args) != 1 {
+		return cli.RunResultHelp
+	}
+
+	if err := i.Init(); err != nil {
+		i.error(i.format, err)
+		return 1
+	}
+	info, err := i.project.Inspect(args[0])
+	if err != nil {
+		i.error(i.format, err)
+		return 1
+	}
+
+	if i.format == formatJSON {
+		var obj interface{}
+		switch i.view {
+		case viewSelectors:
+			obj = info.Functions
+		case viewEvents:
+			obj = info.Events
+		case viewErrors:
+			obj = info.Errors
+		case viewConstructor:
+			obj = map[string]string{"constructor": info.Constructor}
+		case viewCompiler:
+			obj = map[string]interface{}{"compiler": info.Compiler, "optimizer": info.Optimizer}
+		case viewBytecode:
+			obj = map[string]string{"runtime_hash": info.RuntimeHash, "initcode_hash": info.InitcodeHash}
+		default:
+			obj = info
+		}
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			i.UI.Error(err.Error())
+			return 1
+		}
+		i.UI.Output(string(data))
+		return 0
+	}
+
+	i.UI.Output(formatInspect(info, i.view))
+	return 0
+}
+
+func formatInspect(info *core.ContractInfo, view string) string {
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	section := func(title string) {
+		if view == viewAll {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, title+":")
+		}
+	}
+	selectors := func(list []*core.Selector) {
+		for _, sel := range list {
+			selector := sel.Selector
+			if selector == "" {
+				selector = "(anonymous)"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", selector, sel.Signature)
+		}
+	}
+	show := func(v string) bool {
+		return view == viewAll || view == v
+	}
+
+	if view == viewAll {
+		fmt.Fprintf(w, "%s (%s)\n", info.Name, info.Source)
+	}
+	if show(viewCompiler) {
+		section("Compiler")
+		compiler := info.Compiler
+		if compiler == "" {
+			compiler = "unknown (build the project again)"
+		}
+		optimizer := "disabled"
+		if info.Optimizer != nil && info.Optimizer.Enabled {
+			optimizer = fmt.Sprintf("enabled, %d runs", info.Optimizer.Runs)
+		}
+		fmt.Fprintf(w, "  Version\t%s\n", compiler)
+		fmt.Fprintf(w, "  Optimizer\t%s\n", optimizer)
+	}
+	if show(viewBytecode) {
+		section("Bytecode")
+		fmt.Fprintf(w, "  Runtime hash\t%s\n", hashOrNone(info.RuntimeHash))
+		fmt.Fprintf(w, "  Init code hash\t%s\n", hashOrNone(info.InitcodeHash))
+	}
+	if show(viewConstructor) {
+		section("Constructor")
+		constructor := info.Constructor
+		if constructor == "" {
+			constructor = "constructor()"
+		}
+		fmt.Fprintf(w, "  %s\n", constructor)
+	}
+	if show(viewSelectors) {
+		section("Functions")
+		selectors(info.Functions)
+	}
+	if show(viewEvents) {
+		section("Events")
+		selectors(info.Events)
+	}
+	if show(viewErrors) {
+		section("Errors")
+		selectors(info.Errors)
+	}
+	w.Flush()
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func hashOrNone(hash string) string {
+	if hash == "" {
+		return "none"
+	}
+	return hash
+}
+
+// InspectStorageCommand is the command to show the storage layout of a contract
+type InspectStorageCommand struct {
+	*baseCommand
+
+	format string
+}
+
+// Help implements the cli.Command interface
+func (i *InspectStorageCommand) Help() string {
+	return `Usage: greenhouse inspect storage <Contract>
+
+  Show the storage layout of a compiled contract
+
+` + i.Flags().FlagUsages()
+}
+
+// Synopsis implements the cli.Command interface
+func (i *InspectStorageCommand) Synopsis() string {
+	return "Show the storage layout of a contract"
+}
+
+func (i *InspectStorageCommand) Flags() *flag.FlagSet {
+	flags := i.baseCommand.Flags("inspect storage")
+
+	flags.StringVar(&i.format, "format", formatText, "Output format (text or json)")
+
+	return flags
+}
+
+// Run implements the cli.Command interface
+func (i *InspectStorageCommand) Run(args []string) int {
+	flags := i.Flags()
+	if err := flags.Parse(args); err != nil {
+		i.UI.Error(err.Error())
+		return 1
+	}
+	if err := validateFormat(i.format); err != nil {
+		i.UI.Error(err.Error())
+		return 1
+	}
+	args = flags.Args()
+	if len(args) != 1 {
+		i.UI.Error("expected one argument: <Contract>")
+		return 1
+	}
+
+	if err := i.Init(); err != nil {
+		i.error(i.format, err)
+		return 1
+	}
+	slots, err := i.project.StorageLayout(args[0])
+	if err != nil {
+		i.error(i.format, err)
+		return 1
+	}
+
+	if i.format == formatJSON {
+		data, err := json.MarshalIndent(slots, "", "  ")
+		if err != nil {
+			i.UI.Error(err.Error())
+			return 1
+		}
+		i.UI.Output(string(data))
+		return 0
+	}
+	i.UI.Output(formatStorage(slots))
+	return 0
+}
+
+func formatStorage(slots []*core.StorageSlot) string {
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tType\tSlot\tOffset\tBytes\tContract")
+	for _, slot := range slots {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", slot.Name, slot.Type, slot.Slot, slot.Offset, slot.Bytes, slot.Contract)
+	}
+	w.Flush()
+	return strings.TrimRight(buf.String(), "\n")
+}
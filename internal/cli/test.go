@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"strings"
 
 	flag "github.com/spf13/pflag"
 	"github.com/umbracle/greenhouse/internal/core"
@@ -14,6 +13,7 @@ type TestCommand struct {
 
 	run     string
 	verbose bool
+	format  string
 }
 
 // Help implements the cli.Command interface
@@ -35,6 +35,7 @@ func (b *TestCommand) Flags() *flag.FlagSet {
 
 	flags.BoolVarP(&b.verbose, "verbose", "v", false, "Show in stdout the output of the test")
 	flags.StringVar(&b.run, "run", "", "Run specific files")
+	flags.StringVar(&b.format, "format", formatText, "Output format (text or json)")
 
 	return flags
 }
@@ -47,33 +48,73 @@ func (b *TestCommand) Run(args []string) int {
 		return 1
 	}
 
-	if err := b.Init(); err != nil {
+	if err := validateFormat(b.format); err != nil {
 		b.UI.Error(err.Error())
 		return 1
 	}
 
+	if err := b.Init(); err != nil {
+		b.error(b.format, err)
+		return 1
+	}
+
+	if b.format == formatJSON {
+		// build first to report the build events before the tests
+		result, err := b.project.Compile()
+		if err != nil {
+			b.emit(&buildEvent{Type: "build", Success: false, Error: err.Error()})
+			return 1
+		}
+		b.emitBuild(result)
+	}
+
 	input := &core.TestInput{
 		Run: b.run,
 	}
 	outputs, err := b.project.Test(input)
 	if err != nil {
-		b.UI.Error(err.Error())
+		b.error(b.format, err)
 		return 1
 	}
 
+	if b.format == formatJSON {
+		summary := &summaryEvent{Type: "summary"}
+		for _, o := range outputs {
+			b.emitTest(o)
+			if o.Success {
+				summary.Passed++
+			} else {
+				summary.Failed++
+			}
+		}
+		b.emit(summary)
+		if summary.Failed > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	failed := false
 	for _, o := range outputs {
 		res := ""
-		if o.Output.Success {
+		if o.Success {
 			res = "[green]success[reset]"
 		} else {
 			res = "[red]failed[reset]"
+			failed = true
 		}
 		b.UI.Output(b.Colorize().Color(fmt.Sprintf("  %s:%s:%s (%s)", o.Source, o.Contract, o.Method, res)))
+		if !o.Success && o.Reason != "" {
+			b.UI.Output("    " + o.Reason)
+		}
 		if b.verbose {
 			for _, console := range o.Console {
-				b.UI.Output("[" + strings.Join(console.Val, ", ") + "]")
+				b.UI.Output("[" + formatConsole(console) + "]")
 			}
 		}
 	}
+	if failed {
+		return 1
+	}
 	return 0
 }
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+	"github.com/umbracle/greenhouse/internal/standard"
+)
+
+// assertionCheatcode collects the failed assertions of greenhouse/Test.sol
+type assertionCheatcode struct {
+	failures []string
+}
+
+func (a *assertionCheatcode) CanRun(addr evmc.Address) bool {
+	return ethgo.Address(addr) == standard.AssertionAddress
+}
+
+func (a *assertionCheatcode) reset() {
+	a.failures = []string{}
+}
+
+func (a *assertionCheatcode) Run(ctx *state.CallContext, input []byte) ([]byte, uint64, error) {
+	a.failures = append(a.failures, decodeAssertion(input))
+	return nil, 0, nil
+}
+
+// decodeAssertion returns the description of a failed assertion
+func decodeAssertion(input []byte) string {
+	if len(input) < 4 {
+		return "assertion failed"
+	}
+	assertion, ok := standard.AssertionCases[hex.EncodeToString(input[:4])]
+	if !ok {
+		return fmt.Sprintf("assertion failed: unknown assertion 0x%s", hex.EncodeToString(input[:4]))
+	}
+	raw, err := assertion.Type.Decode(input[4:])
+	if err != nil {
+		return fmt.Sprintf("%s failed: failed to decode: %v", assertion.Signature, err)
+	}
+	obj := raw.(map[string]interface{})
+	args := []interface{}{}
+	for i := 0; i < len(obj); i++ {
+		args = append(args, obj[strconv.Itoa(i)])
+	}
+
+	if assertion.Name == "fail" {
+		return fmt.Sprint(args[0])
+	}
+
+	// number of values of the assertion, the optional
+	// argument after them is the error message
+	var num int
+	switch assertion.Name {
+	case "assertTrue", "assertFalse":
+		num = 1
+	case "assertApproxEqAbs", "assertApproxEqRel":
+		num = 3
+	default:
+		num = 2
+	}
+	vals := []string{}
+	for _, arg := range args[:num] {
+		vals = append(vals, formatValue(arg))
+	}
+
+	var detail string
+	switch assertion.Name {
+	case "assertTrue":
+		detail = "expected true, got false"
+	case "assertFalse":
+		detail = "expected false, got true"
+	case "assertEq":
+		detail = fmt.Sprintf("expected %s, got %s", vals[1], vals[0])
+	case "assertNotEq":
+		detail = fmt.Sprintf("expected a value different from %s", vals[1])
+	case "assertLt":
+		detail = fmt.Sprintf("expected %s < %s", vals[0], vals[1])
+	case "assertGt":
+		detail = fmt.Sprintf("expected %s > %s", vals[0], vals[1])
+	case "assertLe":
+		detail = fmt.Sprintf("expected %s <= %s", vals[0], vals[1])
+	case "assertGe":
+		detail = fmt.Sprintf("expected %s >= %s", vals[0], vals[1])
+	case "assertApproxEqAbs":
+		delta := new(big.Int).Sub(args[0].(*big.Int), args[1].(*big.Int))
+		detail = fmt.Sprintf("expected %s ± %s, got %s (delta %s)", vals[1], vals[2], vals[0], delta.Abs(delta))
+	case "assertApproxEqRel":
+		// the max delta is a fixed point value where 1e18 is 100%
+		detail = fmt.Sprintf("expected %s ± %s%%, got %s", vals[1], formatPercent(args[2].(*big.Int)), vals[0])
+	default:
+		detail = strings.Join(vals, ", ")
+	}
+
+	reason := assertion.Name + ": " + detail
+	if len(args) > num {
+		reason = fmt.Sprint(args[num]) + ": " + reason
+	}
+	return reason
+}
+
+// formatPercent formats a fixed point value where 1e18 is 100%
+func formatPercent(val *big.Int) string {
+	percent := new(big.Rat).SetFrac(val, big.NewInt(1e16))
+	str := strings.TrimRight(percent.FloatString(4), "0")
+	return strings.TrimSuffix(str, ".")
+}
+
+// formatValue formats a value decoded from the abi
+func formatValue(val interface{}) string {
+	switch obj := val.(type) {
+	case string:
+		return strconv.Quote(obj)
+	case []byte:
+		return "0x" + hex.EncodeToString(obj)
+	case [32]byte:
+		return "0x" + hex.EncodeToString(obj[:])
+	case ethgo.Address:
+		return obj.String()
+	case *big.Int:
+		return obj.String()
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		elems := []string{}
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, formatValue(v.Index(i).Interface()))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/greenhouse/internal/standard"
+)
+
+func encodeAssertion(t *testing.T, signature string, args ...interface{}) []byte {
+	t.Helper()
+
+	for _, a := range standard.AssertionCases {
+		if a.Signature != signature {
+			continue
+		}
+		data, err := a.Type.Encode(args)
+		assert.NoError(t, err)
+
+		return append(ethgo.Keccak256([]byte(signature))[:4], data...)
+	}
+	t.Fatalf("assertion %s not found", signature)
+	return nil
+}
+
+func TestDecodeAssertion(t *testing.T) {
+	addr := ethgo.HexToAddress("0x1")
+
+	cases := []struct {
+		input  []byte
+		reason string
+	}{
+		{
+			encodeAssertion(t, "assertTrue(bool)", false),
+			"assertTrue: expected true, got false",
+		},
+		{
+			encodeAssertion(t, "assertEq(uint256,uint256)", big.NewInt(1), big.NewInt(2)),
+			"assertEq: expected 2, got 1",
+		},
+		{
+			encodeAssertion(t, "assertEq(int256,int256,string)", big.NewInt(-1), big.NewInt(2), "balance"),
+			"balance: assertEq: expected 2, got -1",
+		},
+		{
+			encodeAssertion(t, "assertEq(address,address)", addr, ethgo.ZeroAddress),
+			"assertEq: expected 0x0000000000000000000000000000000000000000, got 0x0000000000000000000000000000000000000001",
+		},
+		{
+			encodeAssertion(t, "assertEq(string,string)", "a", "b"),
+			`assertEq: expected "b", got "a"`,
+		},
+		{
+			encodeAssertion(t, "assertEq(bytes,bytes)", []byte{0x1}, []byte{}),
+			"assertEq: expected 0x, got 0x01",
+		},
+		{
+			encodeAssertion(t, "assertEq(uint256[],uint256[])", []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(1), big.NewInt(2)}),
+			"assertEq: expected [1, 2], got [1]",
+		},
+		{
+			encodeAssertion(t, "assertLt(uint256,uint256)", big.NewInt(3), big.NewInt(2)),
+			"assertLt: expected 3 < 2",
+		},
+		{
+			encodeAssertion(t, "assertApproxEqAbs(uint256,uint256,uint256)", big.NewInt(10), big.NewInt(15), big.NewInt(2)),
+			"assertApproxEqAbs: expected 15 ± 2, got 10 (delta 5)",
+		},
+		{
+			encodeAssertion(t, "assertApproxEqRel(uint256,uint256,uint256)", big.NewInt(90), big.NewInt(100), big.NewInt(5e16)),
+			"assertApproxEqRel: expected 100 ± 5%, got 90",
+		},
+		{
+			encodeAssertion(t, "fail(string)", "not implemented"),
+			"not implemented",
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.reason, decodeAssertion(c.input))
+	}
+}
+
+func TestAssertionCases(t *testing.T) {
+	// every assertion of Test.sol can be decoded
+	for _, a := range standard.AssertionCases {
+		assert.Equal(t, abi.KindTuple, a.Type.Kind(), a.Signature)
+	}
+	assert.Contains(t, standard.SystemContracts, "greenhouse/Test.sol")
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/umbracle/greenhouse/internal/cache"
+	"github.com/umbracle/greenhouse/internal/solidity"
+)
+
+// DefaultCacheSize is the maximum size in bytes of the compilation cache
+const DefaultCacheSize = 512 * 1024 * 1024
+
+// NewCompileCache returns the compilation cache in the greenhouse home directory
+func NewCompileCache() (*cache.Cache, error) {
+	dirname, err := HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewCache(filepath.Join(dirname, "cache"), DefaultCacheSize)
+}
+
+// compileCacheKey returns the content address of the compiler input. It includes
+// the contents of the sources, the compiler version and all the settings.
+func compileCacheKey(input *solidity.Input) (string, error) {
+	settings, err := json.Marshal(input.Optimizer)
+	if err != nil {
+		return "", err
+	}
+	parts := [][]byte{
+		[]byte(input.Version),
+		settings,
+	}
+	for _, arg := range solidity.Args(input) {
+		parts = append(parts, []byte(arg))
+	}
+
+	// include the contents of the sources, the remapped files and all
+	// the files they import
+	files := append([]string{}, input.Files...)
+	for _, path := range input.Remappings {
+		files = append(files, path)
+	}
+	contents, err := importClosure(files, input.Remappings)
+	if err != nil {
+		return "", err
+	}
+
+	paths := []string{}
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		parts = append(parts, []byte(path), contents[path])
+	}
+	return cache.Key(parts...), nil
+}
+
+// importClosure returns the contents of the files and of all the files
+// they import transitively by path
+func importClosure(files []string, remappings map[string]string) (map[string][]byte, error) {
+	contents := map[string][]byte{}
+	visited := map[string]struct{}{}
+
+	queue := append([]string{}, files...)
+	for len(queue) != 0 {
+		var path string
+		path, queue = queue[0], queue[1:]
+		if _, ok := visited[path]; ok {
+			continue
+		}
+		visited[path] = struct{}{}
+
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			// the compiler reports the imports that are not found
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		contents[path] = content
+
+		for _, im := range cleanImports(string(content), path) {
+			queue = append(queue, importPath(im, remappings))
+		}
+	}
+	return contents, nil
+}
+
+// compile compiles the input or reuses the output from the cache
+// if the same input has been compiled before
+func (p *Project) compile(input *solidity.Input) (*solidity.Output, error) {
+	key, err := compileCacheKey(input)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok, err := p.cache.Get(key)
+	if err != nil {
+		p.logger.Warn("failed to read the compilation cache", "err", err)
+	} else if ok {
+		var output *solidity.Output
+		if err := json.Unmarshal(data, &output); err == nil {
+			p.logger.Debug("compilation cache hit", "key", key)
+			return output, nil
+		}
+		p.logger.Warn("failed to decode cached compilation output", "key", key)
+	}
+
+	output, err := p.sol.Compile(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(output); err != nil {
+		return nil, fmt.Errorf("failed to encode compilation output: %v", err)
+	}
+	if err := p.cache.Put(key, data); err != nil {
+		p.logger.Warn("failed to write the compilation cache", "err", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/solidity"
+)
+
+func TestCompileCacheKey(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-cache-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "A.sol")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("contract A {}"), 0644))
+
+	input := &solidity.Input{
+		Version: "0.8.5",
+		Files:   []string{path},
+	}
+	key, err := compileCacheKey(input)
+	assert.NoError(t, err)
+
+	// same input returns the same key
+	key2, err := compileCacheKey(input)
+	assert.NoError(t, err)
+	assert.Equal(t, key, key2)
+
+	// a different compiler version changes the key
+	key2, err = compileCacheKey(&solidity.Input{Version: "0.8.6", Files: input.Files})
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, key2)
+
+	// a different content changes the key
+	assert.NoError(t, ioutil.WriteFile(path, []byte("contract B {}"), 0644))
+	key2, err = compileCacheKey(input)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, key2)
+}
+
+func TestCompileCacheKey_Imports(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-cache-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	libDir := filepath.Join(tmpDir, "lib")
+	assert.NoError(t, os.Mkdir(libDir, 0755))
+
+	files := map[string]string{
+		"A.sol":        `import "lib/Lib.sol"; contract A {}`,
+		"lib/Lib.sol":  `import "./Deep.sol"; contract Lib {}`,
+		"lib/Deep.sol": `contract Deep {}`,
+	}
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	input := &solidity.Input{
+		Version: "0.8.5",
+		Files:   []string{filepath.Join(tmpDir, "A.sol")},
+		Remappings: map[string]string{
+			"lib/Lib.sol": filepath.Join(libDir, "Lib.sol"),
+		},
+	}
+	key, err := compileCacheKey(input)
+	assert.NoError(t, err)
+
+	// a change in a file imported by a remapped file changes the key
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(libDir, "Deep.sol"), []byte("contract Deep2 {}"), 0644))
+	key2, err := compileCacheKey(input)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, key2)
+}
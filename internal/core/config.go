@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/hcl"
@@ -15,6 +16,70 @@ type Config struct {
 	Contracts    string
 	Solidity     string
 	Dependencies map[string]string
+
+	// Interfaces is the folder for the generated interfaces. It defaults
+	// to the interfaces folder inside the contracts folder.
+	Interfaces string
+
+	// Permissions are the accesses to the host allowed to the tests
+	Permissions *Permissions
+}
+
+// Permissions lists the environment variables, the files and the commands
+// that the cheatcodes of the tests can access. Nothing is allowed by default.
+type Permissions struct {
+	// Env are the names of the environment variables the tests can read
+	Env []string
+
+	// Read are the files or folders of the project the tests can read
+	Read []string
+
+	// Write are the files or folders of the project the tests can write
+	Write []string
+
+	// FFI allows the tests to run local commands
+	FFI bool
+}
+
+// CanReadEnv returns whether the tests can read the environment variable
+func (p *Permissions) CanReadEnv(name string) bool {
+	if p == nil {
+		return false
+	}
+	for _, env := range p.Env {
+		if env == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRead returns whether the tests can read the file of the project.
+// The files that can be written can also be read.
+func (p *Permissions) CanRead(path string) bool {
+	return p != nil && (containsPath(p.Read, path) || containsPath(p.Write, path))
+}
+
+// CanWrite returns whether the tests can write the file of the project
+func (p *Permissions) CanWrite(path string) bool {
+	return p != nil && containsPath(p.Write, path)
+}
+
+// CanRunFFI returns whether the tests can run local commands
+func (p *Permissions) CanRunFFI() bool {
+	return p != nil && p.FFI
+}
+
+// containsPath returns whether the path is one of the paths or is inside
+// one of them. All the paths are relative to the root of the project.
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if p == "." || p == path || strings.HasPrefix(path, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
 }
 
 func DefaultConfig() *Config {
@@ -25,6 +90,14 @@ func DefaultConfig() *Config {
 	}
 }
 
+// InterfacesDir returns the folder for the generated interfaces
+func (c *Config) InterfacesDir() string {
+	if c.Interfaces != "" {
+		return c.Interfaces
+	}
+	return filepath.Join(c.Contracts, "interfaces")
+}
+
 func LoadConfig(path string) (*Config, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -46,6 +119,17 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// only the contracts folder is compiled
+	if config.Interfaces != "" {
+		contracts := config.Contracts
+		if contracts == "" {
+			contracts = DefaultConfig().Contracts
+		}
+		if !containsPath([]string{contracts}, filepath.Clean(config.Interfaces)) {
+			return nil, fmt.Errorf("interfaces folder '%s' is not inside the contracts folder '%s'", config.Interfaces, contracts)
+		}
+	}
+
 	return &config, nil
 }
 
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,3 +18,72 @@ func TestConfig_Merge(t *testing.T) {
 	assert.NoError(t, cfg.Merge(cfg2))
 	assert.Equal(t, "0.5.0", cfg.Solidity)
 }
+
+func TestConfig_InterfacesDir(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, filepath.Join("contracts", "interfaces"), cfg.InterfacesDir())
+
+	assert.NoError(t, cfg.Merge(&Config{Interfaces: "contracts/generated"}))
+	assert.Equal(t, "contracts/generated", cfg.InterfacesDir())
+}
+
+func TestConfig_InterfacesOutsideContracts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "greenhouse.hcl")
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`interfaces = "contracts/generated"`), 0644))
+	_, err = LoadConfig(path)
+	assert.NoError(t, err)
+
+	// the interfaces outside the contracts folder are not compiled
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`interfaces = "interfaces"`), 0644))
+	_, err = LoadConfig(path)
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("contracts = \"src\"\ninterfaces = \"src/interfaces\""), 0644))
+	_, err = LoadConfig(path)
+	assert.NoError(t, err)
+}
+
+func TestConfig_Permissions(t *testing.T) {
+	// nothing is allowed by default
+	cfg := DefaultConfig()
+	assert.False(t, cfg.Permissions.CanReadEnv("HOME"))
+	assert.False(t, cfg.Permissions.CanRead("fixtures"))
+	assert.False(t, cfg.Permissions.CanRunFFI())
+
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "greenhouse.hcl")
+	data := `permissions {
+	env   = ["RPC_URL"]
+	read  = ["fixtures"]
+	write = ["out/"]
+	ffi   = true
+}`
+	assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+
+	fileConfig, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Merge(fileConfig))
+
+	perms := cfg.Permissions
+	assert.True(t, perms.CanReadEnv("RPC_URL"))
+	assert.False(t, perms.CanReadEnv("HOME"))
+
+	assert.True(t, perms.CanRead("fixtures"))
+	assert.True(t, perms.CanRead(filepath.Join("fixtures", "data.json")))
+	assert.False(t, perms.CanRead("fixtures2"))
+
+	// the files that can be written can also be read
+	assert.True(t, perms.CanRead(filepath.Join("out", "result.txt")))
+	assert.True(t, perms.CanWrite(filepath.Join("out", "result.txt")))
+	assert.False(t, perms.CanWrite(filepath.Join("fixtures", "data.json")))
+
+	assert.True(t, perms.CanRunFFI())
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/umbracle/greenhouse/internal/dag"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+var (
+	importStmtRegexp = regexp.MustCompile(`(?m)^[ \t]*import\s[^;]*;[ \t]*\r?\n?`)
+	importAsRegexp   = regexp.MustCompile(`\bas\b`)
+	pragmaStmtRegexp = regexp.MustCompile(`(?m)^[ \t]*pragma\s+solidity\s[^;]*;[ \t]*\r?\n?`)
+	abicoderRegexp   = regexp.MustCompile(`(?m)^[ \t]*pragma\s+(abicoder\s+(v1|v2)|experimental\s+(ABIEncoderV2))\s*;[ \t]*\r?\n?`)
+	spdxRegexp       = regexp.MustCompile(`(?m)^[ \t]*//[ \t]*SPDX-License-Identifier:[ \t]*([^\r\n]*[^\s])[ \t]*\r?\n?`)
+)
+
+// Flatten returns the source file and all its transitive imports in a single
+// source. The imported files come before the files that import them.
+func (p *Project) Flatten(path string) (string, error) {
+	sourcesList, err := p.state.ListSources()
+	if err != nil {
+		return "", err
+	}
+	sources := map[string]*state.Source{}
+	for _, s := range sourcesList {
+		sources[s.Path()] = s
+	}
+	return flatten(path, sources, p.remappings, p.libDirectory)
+}
+
+// flatten resolves the imports the same way as the build does. The sources
+// of the project are taken from the state and any other imported file
+// (i.e. the remapped ones) is parsed.
+func flatten(path string, sources map[string]*state.Source, remappings map[string]string, libDir string) (string, error) {
+	path = filepath.Clean(path)
+
+	// build the import graph with an edge from each imported file
+	// to the file that imports it
+	d := &dag.Dag{}
+	d.AddVertex(path)
+
+	contents := map[string]string{}
+	queue := []string{path}
+	for len(queue) != 0 {
+		var file string
+		file, queue = queue[0], queue[1:]
+		if _, ok := contents[file]; ok {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		contents[file] = string(data)
+
+		// the statements are removed from the flattened file, any import
+		// that is not a dependency in the project would be lost
+		for _, stmt := range importStmtRegexp.FindAllString(string(data), -1) {
+			if importAsRegexp.MatchString(stmt) || !importRegexp.MatchString(stmt) {
+				return "", fmt.Errorf("import '%s' in %s cannot be flattened", strings.TrimSpace(stmt), file)
+			}
+		}
+
+		src, ok := sources[file]
+		if !ok {
+			if src, err = parseSource(string(data), file); err != nil {
+				return "", err
+			}
+		}
+		for _, im := range src.Imports {
+			dst := importPath(im, remappings)
+			if _, err := os.Stat(dst); err != nil {
+				return "", fmt.Errorf("cannot resolve import '%s' in %s", im, file)
+			}
+			d.AddVertex(dst)
+			d.AddEdge(dag.Edge{
+				Src: dst,
+				Dst: file,
+			})
+			queue = append(queue, dst)
+		}
+	}
+
+	order, err := d.TopologicalSort()
+	if err != nil {
+		return "", fmt.Errorf("failed to flatten %s: import cycle", path)
+	}
+
+	licenses := []string{}
+	pragmas := []string{}
+	bodies := []string{}
+
+	// the abi coder applies to the whole flattened file so all
+	// the files must use the same one
+	coderFile, coder, coderStmt := "", "", ""
+
+	for indx, v := range order {
+		file := v.(string)
+		content := contents[file]
+
+		for _, match := range spdxRegexp.FindAllStringSubmatch(content, -1) {
+			licenses = appendUnique(licenses, match[1])
+		}
+		for _, stmt := range pragmaStmtRegexp.FindAllString(content, -1) {
+			pragmas = appendUnique(pragmas, strings.Join(strings.Fields(stmt), " "))
+		}
+
+		fileCoder, fileStmt := "", ""
+		for _, match := range abicoderRegexp.FindAllStringSubmatch(content, -1) {
+			// experimental ABIEncoderV2 is the same as abicoder v2
+			version := match[2]
+			if version == "" {
+				version = "v2"
+			}
+			if fileCoder != "" && fileCoder != version {
+				return "", fmt.Errorf("file %s uses both abi coder v1 and v2", file)
+			}
+			fileCoder, fileStmt = version, strings.Join(strings.Fields(match[0]), " ")
+		}
+		if indx == 0 {
+			coderFile, coder, coderStmt = file, fileCoder, fileStmt
+		} else if fileCoder != coder {
+			return "", fmt.Errorf("files %s and %s use a different abi coder and cannot be flattened", coderFile, file)
+		}
+
+		content = spdxRegexp.ReplaceAllString(content, "")
+		content = pragmaStmtRegexp.ReplaceAllString(content, "")
+		content = abicoderRegexp.ReplaceAllString(content, "")
+		content = importStmtRegexp.ReplaceAllString(content, "")
+
+		bodies = append(bodies, "// File: "+flattenName(file, libDir)+"\n\n"+strings.TrimSpace(content)+"\n")
+	}
+
+	var buf strings.Builder
+	if len(licenses) != 0 {
+		buf.WriteString("// SPDX-License-Identifier: " + strings.Join(licenses, " AND ") + "\n")
+	}
+	for _, pragma := range pragmas {
+		buf.WriteString(pragma + "\n")
+	}
+	if coderStmt != "" {
+		buf.WriteString(coderStmt + "\n")
+	}
+	for _, body := range bodies {
+		buf.WriteString("\n" + body)
+	}
+	return buf.String(), nil
+}
+
+// flattenName returns the name of the file in the flattened source, which
+// is relative to the lib directory or to the root of the project
+func flattenName(file string, libDir string) string {
+	if libDir != "" && strings.HasPrefix(file, libDir+"/") {
+		return strings.TrimPrefix(file, libDir+"/")
+	}
+	if !filepath.IsAbs(file) {
+		return file
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	if name, err := filepath.Rel(root, file); err == nil {
+		return name
+	}
+	return file
+}
+
+func appendUnique(list []string, item string) []string {
+	for _, i := range list {
+		if i == item {
+			return list
+		}
+	}
+	return append(list, item)
+}
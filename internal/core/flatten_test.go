@@ -0,0 +1,190 @@
+package core
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/solidity"
+)
+
+var flattenFiles = map[string]string{
+	"lib/greenhouse/console.sol": "// SPDX-License-Identifier: MIT\npragma solidity >=0.5.0;\n\nlibrary console {\n    function log() internal pure {}\n}\n",
+	"contracts/A.sol":            "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\n\nimport \"./B.sol\";\nimport \"greenhouse/console.sol\";\n\ncontract A is B {\n    function a() public pure {\n        console.log();\n    }\n}\n",
+	"contracts/B.sol":            "// SPDX-License-Identifier: GPL-3.0\npragma  solidity ^0.8.0;\nimport './utils/C.sol';\n\ncontract B is C {}\n",
+	"contracts/utils/C.sol":      "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\n\ncontract C {\n    uint256 public c;\n}\n",
+	"contracts/V1.sol":           "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\npragma abicoder v1;\n\nimport \"./utils/V1Lib.sol\";\n\ncontract V1 is V1Lib {\n    function sum(uint256[] memory a) public pure returns (uint256) {\n        return a.length;\n    }\n}\n",
+	"contracts/utils/V1Lib.sol":  "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\npragma abicoder   v1;\n\ncontract V1Lib {\n    function get(bytes memory b) public pure returns (bytes memory) {\n        return b;\n    }\n}\n",
+}
+
+// setupFlatten writes the flatten files in a temporary directory and
+// moves into it since the project paths are relative to the root
+func setupFlatten(t *testing.T) (map[string]string, func()) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-flatten")
+	assert.NoError(t, err)
+
+	for name, content := range flattenFiles {
+		path := filepath.Join(tmpDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(tmpDir))
+
+	remappings := map[string]string{
+		"greenhouse/console.sol": filepath.Join(tmpDir, "lib/greenhouse/console.sol"),
+	}
+	return remappings, func() {
+		os.Chdir(cwd)
+		os.RemoveAll(tmpDir)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	remappings, closeFn := setupFlatten(t)
+	defer closeFn()
+
+	libDir := filepath.Dir(filepath.Dir(remappings["greenhouse/console.sol"]))
+
+	res, err := flatten("contracts/A.sol", nil, remappings, libDir)
+	assert.NoError(t, err)
+
+	expected := `// SPDX-License-Identifier: MIT AND GPL-3.0
+pragma solidity >=0.5.0;
+pragma solidity ^0.8.0;
+
+// File: greenhouse/console.sol
+
+library console {
+    function log() internal pure {}
+}
+
+// File: contracts/utils/C.sol
+
+contract C {
+    uint256 public c;
+}
+
+// File: contracts/B.sol
+
+contract B is C {}
+
+// File: contracts/A.sol
+
+contract A is B {
+    function a() public pure {
+        console.log();
+    }
+}
+`
+	assert.Equal(t, expected, res)
+
+	// the files outside the lib directory are relative to the project
+	res, err = flatten(filepath.Join(filepath.Dir(libDir), "contracts/utils/C.sol"), nil, remappings, libDir)
+	assert.NoError(t, err)
+	assert.Contains(t, res, "// File: contracts/utils/C.sol\n")
+
+	// the abi coder pragma is declared once for all the files
+	res, err = flatten("contracts/V1.sol", nil, remappings, libDir)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(res, "// SPDX-License-Identifier: MIT\npragma solidity ^0.8.0;\npragma abicoder v1;\n\n"))
+	assert.Equal(t, 1, strings.Count(res, "abicoder"))
+
+	// the files must use the same abi coder
+	assert.NoError(t, ioutil.WriteFile("contracts/G.sol", []byte("pragma solidity ^0.8.0;\npragma abicoder v2;\nimport \"./utils/C.sol\";\n"), 0644))
+	_, err = flatten("contracts/G.sol", nil, remappings, libDir)
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile("contracts/H.sol", []byte("pragma solidity ^0.8.0;\npragma abicoder v2;\nimport \"./utils/V1Lib.sol\";\n"), 0644))
+	_, err = flatten("contracts/H.sol", nil, remappings, libDir)
+	assert.Error(t, err)
+
+	// experimental ABIEncoderV2 is the abi coder v2
+	assert.NoError(t, ioutil.WriteFile("contracts/I.sol", []byte("pragma solidity ^0.8.0;\npragma abicoder v2;\nimport \"./J.sol\";\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile("contracts/J.sol", []byte("pragma solidity ^0.8.0;\npragma experimental ABIEncoderV2;\n"), 0644))
+	_, err = flatten("contracts/I.sol", nil, remappings, libDir)
+	assert.NoError(t, err)
+
+	// aliased imports cannot be flattened
+	assert.NoError(t, ioutil.WriteFile("contracts/D.sol", []byte("pragma solidity ^0.8.0;\nimport {C as X} from \"./utils/C.sol\";\n"), 0644))
+	_, err = flatten("contracts/D.sol", nil, remappings, libDir)
+	assert.Error(t, err)
+
+	// imports that are not resolved by the build cannot be flattened
+	assert.NoError(t, ioutil.WriteFile("contracts/E.sol", []byte("pragma solidity ^0.8.0;\nimport {C} from \"./utils/C.sol\";\n"), 0644))
+	_, err = flatten("contracts/E.sol", nil, remappings, libDir)
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile("contracts/F.sol", []byte("pragma solidity ^0.8.0;\nimport \"other/lib.sol\";\n"), 0644))
+	_, err = flatten("contracts/F.sol", nil, remappings, libDir)
+	assert.Error(t, err)
+}
+
+func TestFlatten_Bytecode(t *testing.T) {
+	remappings, closeFn := setupFlatten(t)
+	defer closeFn()
+
+	libDir := filepath.Dir(filepath.Dir(remappings["greenhouse/console.sol"]))
+
+	dirname, err := HomeDir()
+	assert.NoError(t, err)
+	sol := solidity.NewSolidity(dirname)
+
+	cases := map[string][]string{
+		"contracts/A.sol":  {"A", "B", "C"},
+		"contracts/V1.sol": {"V1", "V1Lib"},
+	}
+	for path, names := range cases {
+		input := &solidity.Input{
+			Version:    "0.8.4",
+			Files:      []string{path},
+			Remappings: remappings,
+		}
+		output, err := sol.Compile(input)
+		if err != nil && !sol.Exists(input.Version) {
+			t.Skipf("solidity %s is not available: %v", input.Version, err)
+		}
+		assert.NoError(t, err)
+
+		res, err := flatten(path, nil, remappings, libDir)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile("Flattened.sol", []byte(res), 0644))
+
+		flatOutput, err := sol.Compile(&solidity.Input{
+			Version: input.Version,
+			Files:   []string{"Flattened.sol"},
+		})
+		assert.NoError(t, err)
+
+		for _, name := range names {
+			var artifact *solidity.Artifact
+			for fullName, a := range output.Contracts {
+				if strings.HasSuffix(fullName, ":"+name) {
+					artifact = a
+				}
+			}
+			flatArtifact := flatOutput.Contracts["Flattened.sol:"+name]
+			if !assert.NotNil(t, artifact) || !assert.NotNil(t, flatArtifact) {
+				continue
+			}
+			// the metadata hash includes the file names
+			assert.Equal(t, stripMetadata(t, artifact.Bin), stripMetadata(t, flatArtifact.Bin))
+			assert.Equal(t, stripMetadata(t, artifact.BinRuntime), stripMetadata(t, flatArtifact.BinRuntime))
+		}
+	}
+}
+
+// stripMetadata removes the cbor metadata at the end of the bytecode
+func stripMetadata(t *testing.T, bin string) []byte {
+	code, err := hex.DecodeString(bin)
+	assert.NoError(t, err)
+
+	size := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	return code[:len(code)-size-2]
+}
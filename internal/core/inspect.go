@@ -0,0 +1,117 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/greenhouse/internal/solidity"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+// Selector is the signature of a function, event or error and
+// its selector (or topic for events)
+type Selector struct {
+	Signature string `json:"signature"`
+	Selector  string `json:"selector"`
+}
+
+// ContractInfo is the description of a compiled contract
+type ContractInfo struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+
+	// Compiler is the version of the compiler. It is empty if the
+	// contract was compiled by an older version of greenhouse.
+	Compiler  string              `json:"compiler"`
+	Optimizer *solidity.Optimizer `json:"optimizer"`
+
+	Constructor string      `json:"constructor"`
+	Functions   []*Selector `json:"functions"`
+	Events      []*Selector `json:"events"`
+	Errors      []*Selector `json:"errors"`
+
+	// RuntimeHash and InitcodeHash are the keccak256 hashes of the code.
+	// They are empty if the code has unlinked libraries.
+	RuntimeHash  string `json:"runtime_hash"`
+	InitcodeHash string `json:"initcode_hash"`
+}
+
+// Inspect returns the description of the contract from the build
+// state without compiling the project
+func (p *Project) Inspect(name string) (*ContractInfo, error) {
+	contract, err := p.Contract(name)
+	if err != nil {
+		return nil, err
+	}
+	return inspectContract(contract)
+}
+
+func inspectContract(contract *state.Contract) (*ContractInfo, error) {
+	contractABI, err := abi.NewABI(contract.Abi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse abi of '%s': %v", contract.Name, err)
+	}
+
+	info := &ContractInfo{
+		Name:         contract.Name,
+		Source:       contract.Dir + "/" + contract.Filename,
+		Compiler:     contract.Compiler,
+		Optimizer:    contract.Optimizer,
+		Functions:    []*Selector{},
+		Events:       []*Selector{},
+		Errors:       []*Selector{},
+		RuntimeHash:  codeHash(contract.BinRuntime),
+		InitcodeHash: codeHash(contract.Bin),
+	}
+
+	if contractABI.Constructor != nil {
+		info.Constructor = (&abi.Method{Name: "constructor", Inputs: contractABI.Constructor.Inputs}).Sig()
+	}
+	for sig, method := range contractABI.MethodsBySignature {
+		info.Functions = append(info.Functions, &Selector{
+			Signature: sig,
+			Selector:  "0x" + hex.EncodeToString(method.ID()),
+		})
+	}
+	for _, event := range contractABI.Events {
+		sel := &Selector{
+			Signature: event.Sig(),
+		}
+		// anonymous events do not have a topic
+		if !event.Anonymous {
+			sel.Selector = event.ID().String()
+		}
+		info.Events = append(info.Events, sel)
+	}
+	for _, e := range contractABI.Errors {
+		method := &abi.Method{Name: e.Name, Inputs: e.Inputs}
+		info.Errors = append(info.Errors, &Selector{
+			Signature: method.Sig(),
+			Selector:  "0x" + errorSelector(e),
+		})
+	}
+
+	for _, list := range [][]*Selector{info.Functions, info.Events, info.Errors} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Signature < list[j].Signature
+		})
+	}
+	return info, nil
+}
+
+// codeHash returns the keccak256 hash of the hex encoded code
+func codeHash(code string) string {
+	if code == "" {
+		return ""
+	}
+	buf, err := hex.DecodeString(strings.TrimPrefix(code, "0x"))
+	if err != nil {
+		// the code has placeholders for the libraries
+		return ""
+	}
+	return "0x" + hex.EncodeToString(ethgo.Keccak256(buf))
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestInspectContract(t *testing.T) {
+	contract := &state.Contract{
+		Name:     "Token",
+		Dir:      "contracts",
+		Filename: "Token.sol",
+		Abi: `[
+			{"type":"constructor","inputs":[{"name":"supply","type":"uint256"}],"stateMutability":"nonpayable"},
+			{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+			{"type":"function","name":"balanceOf","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+			{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+			{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+		]`,
+		Bin:        "",
+		BinRuntime: "00",
+		Compiler:   "0.8.4+commit.c7e474f2",
+	}
+
+	info, err := inspectContract(contract)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "contracts/Token.sol", info.Source)
+	assert.Equal(t, "0.8.4+commit.c7e474f2", info.Compiler)
+	assert.Equal(t, "constructor(uint256)", info.Constructor)
+	assert.Equal(t, []*Selector{
+		{Signature: "balanceOf(address)", Selector: "0x70a08231"},
+		{Signature: "transfer(address,uint256)", Selector: "0xa9059cbb"},
+	}, info.Functions)
+	assert.Equal(t, []*Selector{
+		{Signature: "Transfer(address,address,uint256)", Selector: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+	}, info.Events)
+	assert.Equal(t, []*Selector{
+		{Signature: "InsufficientBalance(uint256,uint256)", Selector: "0xcf479181"},
+	}, info.Errors)
+
+	// keccak256(0x00)
+	assert.Equal(t, "0xbc36789e7a1e281436464229828f817d6612f7b477d66591ff96a9e064bcc98a", info.RuntimeHash)
+	assert.Equal(t, "", info.InitcodeHash)
+}
@@ -2,15 +2,74 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/umbracle/greenhouse/internal/state"
 )
 
+// metadataVersion is the current version of the metadata schema. Bump it
+// (and add a migration) whenever the format of the stored objects changes.
+const metadataVersion = 3
+
+// MetadataPath is the path of the file that stores the build metadata
+var MetadataPath = filepath.Join(".greenhouse", "metadata.json")
+
+var (
+	errMetadataCorrupted = errors.New("metadata is corrupted")
+	errMetadataVersion   = errors.New("metadata version is not supported")
+)
+
 type metadataFormat struct {
+	Version   int
 	Sources   []*state.Source
 	Contracts []*state.Contract
 }
 
+// metadataMigration upgrades a raw metadata object from version i to i+1
+type metadataMigration func(raw map[string]interface{}) error
+
+// metadataMigrations is the list of migrations indexed by the version they upgrade from
+var metadataMigrations = map[int]metadataMigration{
+	// version 0 is the unversioned format. The objects are the same.
+	0: func(raw map[string]interface{}) error {
+		return nil
+	},
+	// version 2 stores the storage layout of the contracts. The sources
+	// are tainted to compile them again with the layout.
+	1: taintSourcesMigration,
+	// version 3 stores the compiler version and settings of the contracts
+	2: taintSourcesMigration,
+}
+
+// taintSourcesMigration marks all the sources as modified so that
+// the next build compiles them again
+func taintSourcesMigration(raw map[string]interface{}) error {
+	sources, ok := raw["Sources"].([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, src := range sources {
+		obj, ok := src.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("source is not an object")
+		}
+		obj["Tainted"] = true
+	}
+	return nil
+}
+
+func newMetadata() *metadataFormat {
+	return &metadataFormat{
+		Version:   metadataVersion,
+		Sources:   []*state.Source{},
+		Contracts: []*state.Contract{},
+	}
+}
+
 func getMetadataRaw(s *state.State) ([]byte, error) {
 	sources, err := s.ListSources()
 	if err != nil {
@@ -22,6 +81,7 @@ func getMetadataRaw(s *state.State) ([]byte, error) {
 		return nil, err
 	}
 	out := &metadataFormat{
+		Version:   metadataVersion,
 		Contracts: contracts,
 		Sources:   sources,
 	}
@@ -31,3 +91,157 @@ func getMetadataRaw(s *state.State) ([]byte, error) {
 	}
 	return data, nil
 }
+
+// writeMetadata writes the metadata of the state in path. The file is written
+// first to a temporary file and renamed after so that a partial write
+// never replaces a valid metadata file.
+func writeMetadata(path string, s *state.State) error {
+	data, err := getMetadataRaw(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// the temporary file is created with 0600
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readMetadata reads and migrates the metadata file in path. It returns
+// errMetadataCorrupted or errMetadataVersion if the file cannot be used.
+func readMetadata(path string) (*metadataFormat, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMetadataCorrupted, err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("%w: empty object", errMetadataCorrupted)
+	}
+
+	version := 0
+	if val, ok := raw["Version"]; ok {
+		num, ok := val.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: version is not a number", errMetadataCorrupted)
+		}
+		version = int(num)
+	}
+	if version > metadataVersion || version < 0 {
+		return nil, fmt.Errorf("%w: %d", errMetadataVersion, version)
+	}
+
+	for ; version < metadataVersion; version++ {
+		migration, ok := metadataMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no migration from version %d", errMetadataVersion, version)
+		}
+		if err := migration(raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate metadata from version %d: %v", version, err)
+		}
+	}
+	raw["Version"] = metadataVersion
+
+	// decode the migrated object into the current format
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, err
+	}
+	var metadata *metadataFormat
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMetadataCorrupted, err)
+	}
+	return metadata, nil
+}
+
+// MetadataStatus is the health status of the metadata store
+type MetadataStatus string
+
+const (
+	MetadataOk          MetadataStatus = "ok"
+	MetadataMissing     MetadataStatus = "missing"
+	MetadataOutdated    MetadataStatus = "outdated"
+	MetadataCorrupted   MetadataStatus = "corrupted"
+	MetadataUnsupported MetadataStatus = "unsupported"
+)
+
+// MetadataHealth is the report of the metadata store
+type MetadataHealth struct {
+	Path      string
+	Status    MetadataStatus
+	Version   int
+	Sources   int
+	Contracts int
+	Err       error
+}
+
+// CheckMetadata reports the health of the metadata store in path
+// without modifying it.
+func CheckMetadata(path string) *MetadataHealth {
+	health := &MetadataHealth{
+		Path: path,
+	}
+
+	exists, err := existsFile(path)
+	if err != nil {
+		health.Status = MetadataCorrupted
+		health.Err = err
+		return health
+	}
+	if !exists {
+		health.Status = MetadataMissing
+		return health
+	}
+
+	// find the stored version before any migration is applied
+	if data, err := ioutil.ReadFile(path); err == nil {
+		var obj struct {
+			Version int
+		}
+		if json.Unmarshal(data, &obj) == nil {
+			health.Version = obj.Version
+		}
+	}
+
+	metadata, err := readMetadata(path)
+	if err != nil {
+		health.Err = err
+		if errors.Is(err, errMetadataVersion) {
+			health.Status = MetadataUnsupported
+		} else {
+			health.Status = MetadataCorrupted
+		}
+		return health
+	}
+
+	health.Sources = len(metadata.Sources)
+	health.Contracts = len(metadata.Contracts)
+	if health.Version != metadataVersion {
+		health.Status = MetadataOutdated
+	} else {
+		health.Status = MetadataOk
+	}
+	return health
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestMetadata_WriteAndRead(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-metadata")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	s, err := state.NewState()
+	assert.NoError(t, err)
+	assert.NoError(t, s.UpsertSource(&state.Source{Dir: "contracts", Filename: "A.sol"}))
+	assert.NoError(t, s.UpsertContract(&state.Contract{Name: "A"}))
+
+	path := filepath.Join(tmpDir, "metadata.json")
+	assert.NoError(t, writeMetadata(path, s))
+
+	metadata, err := readMetadata(path)
+	assert.NoError(t, err)
+	assert.Equal(t, metadataVersion, metadata.Version)
+	assert.Len(t, metadata.Sources, 1)
+	assert.Len(t, metadata.Contracts, 1)
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, os.FileMode(0644), files[0].Mode().Perm())
+
+	assert.Equal(t, MetadataOk, CheckMetadata(path).Status)
+}
+
+func TestMetadata_Recovery(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-metadata")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "metadata.json")
+
+	cases := []struct {
+		content string
+		status  MetadataStatus
+	}{
+		{`{"Sources": [], "Contracts": []}`, MetadataOutdated},
+		{`{"Sources": [], "Contr`, MetadataCorrupted},
+		{`{"Version": 1000, "Sources": []}`, MetadataUnsupported},
+		{`{"Version": 1, "Sources": [], "Contracts": []}`, MetadataOutdated},
+		{`{"Version": 2, "Sources": [], "Contracts": []}`, MetadataOutdated},
+		{`{"Version": 3, "Sources": {}}`, MetadataCorrupted},
+	}
+	for _, c := range cases {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(c.content), 0644))
+		assert.Equal(t, c.status, CheckMetadata(path).Status, c.content)
+	}
+
+	assert.Equal(t, MetadataMissing, CheckMetadata(filepath.Join(tmpDir, "none.json")).Status)
+}
+
+func TestMetadata_MigrateTaintsSources(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-metadata")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "metadata.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"Version": 1, "Sources": [{"Dir": "contracts", "Filename": "A.sol", "Tainted": false}]}`), 0644))
+
+	metadata, err := readMetadata(path)
+	assert.NoError(t, err)
+	assert.Len(t, metadata.Sources, 1)
+	assert.True(t, metadata.Sources[0].Tainted)
+}
@@ -3,7 +3,6 @@ package core
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -13,6 +12,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/umbracle/greenhouse/internal/cache"
 	"github.com/umbracle/greenhouse/internal/solidity"
 	"github.com/umbracle/greenhouse/internal/standard"
 	"github.com/umbracle/greenhouse/internal/state"
@@ -25,6 +25,9 @@ type Project struct {
 	// wrapper to compile solidity
 	sol *solidity.Solidity
 
+	// cache of compilation outputs shared by all the projects
+	cache *cache.Cache
+
 	// state holds the structure of sources and contracts
 	state *state.State
 
@@ -51,14 +54,17 @@ func NewProject(logger hclog.Logger, config *Config) (*Project, error) {
 	}
 	p.state = state
 
-	dirname, err := os.UserHomeDir()
+	dirname, err := HomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %v", err)
+		return nil, err
 	}
-	dirname = filepath.Join(dirname, ".greenhouse")
 
 	p.sol = solidity.NewSolidity(dirname)
 
+	if p.cache, err = NewCompileCache(); err != nil {
+		return nil, err
+	}
+
 	// write the standard contracts to system folder
 	libDir := filepath.Join(dirname, "lib")
 	for c, code := range standard.SystemContracts {
@@ -83,6 +89,59 @@ func NewProject(logger hclog.Logger, config *Config) (*Project, error) {
 	return p, nil
 }
 
+// HomeDir returns the greenhouse directory in the home of the user
+func HomeDir() (string, error) {
+	dirname, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(dirname, ".greenhouse"), nil
+}
+
+// Contracts returns the compiled contracts of the project. It does
+// not include the greenhouse system contracts.
+func (p *Project) Contracts() ([]*state.Contract, error) {
+	contracts, err := p.state.ListContracts()
+	if err != nil {
+		return nil, err
+	}
+	res := []*state.Contract{}
+	for _, contract := range contracts {
+		if strings.HasPrefix(contract.Dir, p.libDirectory) {
+			continue
+		}
+		res = append(res, contract)
+	}
+	return res, nil
+}
+
+// Contract returns the compiled contract of the project with the given name
+func (p *Project) Contract(name string) (*state.Contract, error) {
+	contracts, err := p.Contracts()
+	if err != nil {
+		return nil, err
+	}
+	var found *state.Contract
+	for _, contract := range contracts {
+		if contract.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("contract '%s' is defined in '%s' and '%s'", name, found.Filename, contract.Filename)
+		}
+		found = contract
+	}
+	if found == nil {
+		return nil, fmt.Errorf("contract '%s' not found", name)
+	}
+	return found, nil
+}
+
+// Config returns the configuration of the project
+func (p *Project) Config() *Config {
+	return p.config
+}
+
 func (p *Project) initSources() error {
 	// create the config dir if does not exists
 	if err := os.MkdirAll(".greenhouse", os.ModePerm); err != nil {
@@ -197,26 +256,23 @@ func existsFile(path string) (bool, error) {
 }
 
 func (p *Project) loadMetadata() error {
-	var metadata *metadataFormat
+	metadata := newMetadata()
 
-	metadataPath := filepath.Join(".greenhouse", "metadata.json")
-	exists, err := existsFile(metadataPath)
+	exists, err := existsFile(MetadataPath)
 	if err != nil {
 		return err
 	}
 	if exists {
 		// load the metadata from a file
-		data, err := ioutil.ReadFile(metadataPath)
+		stored, err := readMetadata(MetadataPath)
 		if err != nil {
-			return err
-		}
-		if err := json.Unmarshal(data, &metadata); err != nil {
-			return err
-		}
-	} else {
-		metadata = &metadataFormat{
-			Sources:   []*state.Source{},
-			Contracts: []*state.Contract{},
+			if !errors.Is(err, errMetadataCorrupted) && !errors.Is(err, errMetadataVersion) {
+				return err
+			}
+			// start from an empty metadata so that the whole project is rebuilt
+			p.logger.Warn("failed to load metadata, rebuilding the project", "err", err)
+		} else {
+			metadata = stored
 		}
 	}
 
@@ -236,6 +292,24 @@ func (p *Project) loadMetadata() error {
 	return nil
 }
 
+// cleanImports returns the imports of the file at path with the relative file
+// names converted to their path with respect to the contracts repo
+// (i.e ./d.sol to ./contracts/d.sol)
+func cleanImports(content, path string) []string {
+	parentPath := filepath.Dir(path)
+
+	imports := []string{}
+	for _, im := range parseDependencies(content) {
+		// local
+		if !strings.HasPrefix(im, ".") {
+			imports = append(imports, im)
+		} else {
+			imports = append(imports, filepath.Join(parentPath, im))
+		}
+	}
+	return imports
+}
+
 func parseSource(content, path string) (*state.Source, error) {
 	// new file
 	dir, filename := filepath.Dir(path), filepath.Base(path)
@@ -245,21 +319,6 @@ func parseSource(content, path string) (*state.Source, error) {
 		return nil, err
 	}
 
-	oldImports := parseDependencies(string(content))
-	parentPath := filepath.Dir(path)
-
-	// clean the imports so that we can convert relative file names to
-	// their path with respect to the contracts repo (i.e ./d.sol to ./contracts/d.sol)
-	cleanImports := []string{}
-	for _, im := range oldImports {
-		// local
-		if !strings.HasPrefix(im, ".") {
-			cleanImports = append(cleanImports, im)
-		} else {
-			cleanImports = append(cleanImports, filepath.Join(parentPath, im))
-		}
-	}
-
 	pragma, err := parsePragma(string(content))
 	if err != nil {
 		return nil, err
@@ -269,7 +328,7 @@ func parseSource(content, path string) (*state.Source, error) {
 		Dir:      dir,
 		Filename: filename,
 		Version:  pragma,
-		Imports:  cleanImports,
+		Imports:  cleanImports(content, path),
 		ModTime:  file.ModTime(),
 	}
 	return source, nil
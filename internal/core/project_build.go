@@ -51,17 +51,17 @@ func (p *Project) findLocalDiff() error {
 }
 
 // Compile compiles the application
-func (p *Project) Compile() error {
+func (p *Project) Compile() (*CompileResult, error) {
 	resp, err := p.compileImpl()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// write artifacts!
-	for name, contract := range resp.Contracts {
+	for fullName, contract := range resp.Contracts {
 		// name has the format <path>:<contract>
 		// remove the contract name
-		spl := strings.Split(name, ":")
+		spl := strings.Split(fullName, ":")
 		path, name := spl[0], spl[1]
 
 		// trim the lib directory from the path (if exists)
@@ -70,33 +70,36 @@ func (p *Project) Compile() error {
 		// remove the contracts path in the destination name
 		sourcePath := filepath.Join(".greenhouse", path)
 		if err := os.MkdirAll(sourcePath, 0755); err != nil {
-			return err
+			return nil, err
 		}
 
 		// write the contract file
 		raw, err := json.Marshal(contract)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if err := ioutil.WriteFile(filepath.Join(sourcePath, name+".json"), raw, 0644); err != nil {
-			return err
+		artifactPath := filepath.Join(sourcePath, name+".json")
+		if err := ioutil.WriteFile(artifactPath, raw, 0644); err != nil {
+			return nil, err
 		}
+		resp.Artifacts[fullName] = artifactPath
 	}
 
 	// write metadata
-	metadataRaw, err := getMetadataRaw(p.state)
-	if err != nil {
-		return err
-	}
-	if err := ioutil.WriteFile(filepath.Join(".greenhouse", "metadata.json"), metadataRaw, 0644); err != nil {
-		return err
+	if err := writeMetadata(MetadataPath, p.state); err != nil {
+		return nil, err
 	}
-	return nil
-
+	return resp, nil
 }
 
 type CompileResult struct {
 	Contracts map[string]*state.Contract
+
+	// Artifacts is the path of the artifact file of each contract
+	Artifacts map[string]string
+
+	// Diagnostics are the warnings reported by the compiler
+	Diagnostics []string
 }
 
 func (p *Project) compileImpl() (*CompileResult, error) {
@@ -182,6 +185,7 @@ func (p *Project) compileImpl() (*CompileResult, error) {
 	}
 
 	contracts := map[string]*state.Contract{}
+	diagnostics := []string{}
 
 	// generate the outputs and compile
 	for _, comp := range components {
@@ -206,10 +210,11 @@ func (p *Project) compileImpl() (*CompileResult, error) {
 			Files:      comp,
 			Remappings: remappings,
 		}
-		output, err := p.sol.Compile(input)
+		output, err := p.compile(input)
 		if err != nil {
 			return nil, err
 		}
+		diagnostics = append(diagnostics, output.Warnings...)
 
 		for _, i := range comp {
 			src := sources[i].Copy()
@@ -233,6 +238,10 @@ func (p *Project) compileImpl() (*CompileResult, error) {
 				Abi:        string(c.Abi),
 				Bin:        c.Bin,
 				BinRuntime: c.BinRuntime,
+
+				StorageLayout: c.StorageLayout,
+				Compiler:      output.Version,
+				Optimizer:     input.Optimizer,
 			}
 			if err := p.state.UpsertContract(ctnr); err != nil {
 				return nil, err
@@ -242,7 +251,9 @@ func (p *Project) compileImpl() (*CompileResult, error) {
 	}
 
 	resp := &CompileResult{
-		Contracts: contracts,
+		Contracts:   contracts,
+		Artifacts:   map[string]string{},
+		Diagnostics: diagnostics,
 	}
 	return resp, nil
 }
@@ -268,6 +279,15 @@ func parseDependencies(contract string) []string {
 	return clean
 }
 
+// importPath returns the path of the file of a clean import, which is
+// either remapped or a path with respect to the contracts repo
+func importPath(im string, remappings map[string]string) string {
+	if path, ok := remappings[im]; ok {
+		return path
+	}
+	return im
+}
+
 func parsePragma(contract string) ([]string, error) {
 	res := pragmaRegexp.FindStringSubmatch(contract)
 	if len(res) == 0 {
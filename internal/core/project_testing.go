@@ -7,7 +7,9 @@ import (
 	"math/big"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/evmc/v10/bindings/go/evmc"
 	"github.com/umbracle/ethgo"
@@ -49,6 +51,18 @@ type TestOutput struct {
 	Method   string
 	Console  []*ConsoleOutput
 	Output   *state.Output
+
+	// Success is whether the test passed
+	Success bool
+
+	// Reason is the reason of the failure of the test
+	Reason string
+
+	// GasUsed is the gas consumed by the test call
+	GasUsed uint64
+
+	// Duration is the time spent running the test call
+	Duration time.Duration
 }
 
 type TestInput struct {
@@ -56,9 +70,15 @@ type TestInput struct {
 }
 
 func (p *Project) Test(input *TestInput) ([]*TestOutput, error) {
-	if err := p.Compile(); err != nil {
+	if _, err := p.Compile(); err != nil {
 		return nil, err
 	}
+	return p.runTests(input)
+}
+
+// runTests runs the tests of the compiled contracts. Every test contract
+// is deployed once and its tests run from a copy of the state after setUp.
+func (p *Project) runTests(input *TestInput) ([]*TestOutput, error) {
 	targets := testTargets{}
 
 	runExpr, err := regexp.Compile(input.Run)
@@ -72,15 +92,21 @@ func (p *Project) Test(input *TestInput) ([]*TestOutput, error) {
 	if err != nil {
 		return nil, err
 	}
-	for _, contract := range contracts {
-		if !strings.HasPrefix(contract.Name, "Test") {
-			continue
-		}
+	// the custom errors of the project decode the reverts of the tests
+	projectErrors := map[string]*abi.Error{}
 
+	for _, contract := range contracts {
 		contractABI, err := abi.NewABI(string(contract.Abi))
 		if err != nil {
 			return nil, err
 		}
+		for _, e := range contractABI.Errors {
+			projectErrors[errorSelector(e)] = e
+		}
+
+		if !strings.HasPrefix(contract.Name, "Test") {
+			continue
+		}
 
 		// check if there is any contract that matches the regexp
 		validContract := false
@@ -114,9 +140,20 @@ func (p *Project) Test(input *TestInput) ([]*TestOutput, error) {
 	console := &consoleCheatcode{}
 	console.reset()
 
+	assertions := &assertionCheatcode{}
+	assertions.reset()
+
+	vm := newVMCheatcode(p)
+
 	opts := []state.ConfigOption{
-		state.WithRevision(evmc.Istanbul),
+		// london enables block.basefee, the gas costs are still the ones of istanbul
+		state.WithRevision(evmc.London),
 		state.WithCheatcode(console),
+		state.WithCheatcode(assertions),
+		state.WithCheatcode(vm),
+		state.WithHook(vm),
+		state.WithMock(vm),
+		state.WithTracer(vm),
 	}
 	txn := state.NewTransition(opts...)
 
@@ -148,8 +185,87 @@ func (p *Project) Test(input *TestInput) ([]*TestOutput, error) {
 		targetsByAddr[target.Addr] = target
 	}
 
+	// discard the assertions and pranks of the constructors
+	assertions.reset()
+	vm.reset()
+
+	// run calls a method of the test contract and reports the failures of
+	// the call, the assertions and the expectations of the cheatcodes
+	run := func(target *testTarget, methodName string, input []byte) *TestOutput {
+		// the errors of the test contract take precedence over the ones
+		// with the same selector in other contracts
+		errors := map[string]*abi.Error{}
+		for selector, e := range projectErrors {
+			errors[selector] = e
+		}
+		for _, e := range target.Abi.Errors {
+			errors[errorSelector(e)] = e
+		}
+
+		to := evmc.Address(target.Addr)
+		msg := &state.Message{
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+			Gas:      1000000000,
+			From:     evmc.Address(sender),
+			To:       &to,
+			Input:    input,
+		}
+		gas := msg.Gas
+
+		now := time.Now()
+		output := txn.Apply(msg)
+		duration := time.Since(now)
+
+		testOutput := &TestOutput{
+			Source:   target.Source,
+			Contract: target.Name,
+			Method:   methodName,
+			Output:   output,
+			Console:  console.outputs,
+			Success:  output.Success,
+			GasUsed:  gas - output.GasLeft,
+			Duration: duration,
+		}
+		if !output.Success {
+			testOutput.Reason = decodeRevertWithErrors(output.ReturnValue, errors)
+		}
+		// a failed assertion or expectation fails the test even if the revert was caught
+		failures := append(assertions.failures, vm.finish()...)
+		if len(failures) != 0 {
+			testOutput.Success = false
+			testOutput.Reason = strings.Join(failures, "; ")
+		}
+		console.reset()
+		assertions.reset()
+		return testOutput
+	}
+
+	// every test contract starts from the state after the deployments
+	deployed := txn.Txn().Snapshot()
+	deployedEnv := *txn.Context()
+
 	result := []*TestOutput{}
 	for _, target := range targets {
+		if err := txn.Txn().RevertToSnapshot(deployed); err != nil {
+			return nil, err
+		}
+		*txn.Context() = deployedEnv
+
+		// setUp runs once for the contract and its failure fails all the tests
+		if setUp, ok := target.Abi.Methods["setUp"]; ok {
+			testOutput := run(target, "setUp", setUp.ID())
+			if !testOutput.Success {
+				result = append(result, testOutput)
+				vm.reset()
+				continue
+			}
+		}
+
+		// every test starts from the state after setUp
+		setUp := txn.Txn().Snapshot()
+		setUpEnv := *txn.Context()
+		setUpVM := vm.saveSetUp()
 
 		// sort the methods to get a deterministic output
 		methodNames := sort.StringSlice{}
@@ -168,26 +284,25 @@ func (p *Project) Test(input *TestInput) ([]*TestOutput, error) {
 				continue
 			}
 
-			to := evmc.Address(target.Addr)
-			msg := &state.Message{
-				GasPrice: big.NewInt(1),
-				Value:    big.NewInt(0),
-				Gas:      1000000000,
-				From:     evmc.Address(sender),
-				To:       &to,
-				Input:    method.ID(),
+			if err := txn.Txn().RevertToSnapshot(setUp); err != nil {
+				return nil, err
 			}
-			output := txn.Apply(msg)
-
-			result = append(result, &TestOutput{
-				Source:   target.Source,
-				Contract: target.Name,
-				Method:   methodName,
-				Output:   output,
-				Console:  console.outputs,
-			})
-			console.reset()
+			*txn.Context() = setUpEnv
+			vm.restoreSetUp(setUpVM)
+
+			testOutput := run(target, methodName, method.ID())
+			if strings.HasPrefix(methodName, "testFail") {
+				// the tests with the testFail prefix pass if they fail
+				testOutput.Success = !testOutput.Success
+				if testOutput.Success {
+					testOutput.Reason = ""
+				} else {
+					testOutput.Reason = "testFail: expected the test to fail, but it succeeded"
+				}
+			}
+			result = append(result, testOutput)
 		}
+		vm.reset()
 	}
 
 	return result, nil
@@ -214,24 +329,32 @@ func (c *consoleCheatcode) addError(err error) {
 	c.outputs = append(c.outputs, &ConsoleOutput{Err: err})
 }
 
-func (c *consoleCheatcode) Run(addr evmc.Address, input []byte) {
+func (c *consoleCheatcode) Run(ctx *state.CallContext, input []byte) ([]byte, uint64, error) {
+	if len(input) < 4 {
+		c.addError(fmt.Errorf("input too short"))
+		return nil, 0, nil
+	}
 	sig := hex.EncodeToString(input[:4])
 	logSig, ok := standard.LogCases[sig]
 	if !ok {
 		c.addError(fmt.Errorf("sig %s not found", sig))
-		return
+		return nil, 0, nil
 	}
 	input = input[4:]
 	raw, err := logSig.Decode(input)
 	if err != nil {
 		c.addError(fmt.Errorf("failed to decode: %v", err))
-		return
+		return nil, 0, nil
 	}
+	// the arguments of the log calls are not named, iterate
+	// them by position to keep the order of the call
+	args := raw.(map[string]interface{})
 	val := []string{}
-	for _, v := range raw.(map[string]interface{}) {
-		val = append(val, fmt.Sprint(v))
+	for i := 0; i < len(args); i++ {
+		val = append(val, fmt.Sprint(args[strconv.Itoa(i)]))
 	}
 	c.outputs = append(c.outputs, &ConsoleOutput{
 		Val: val,
 	})
+	return nil, 0, nil
 }
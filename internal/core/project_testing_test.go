@@ -0,0 +1,205 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/greenhouse/internal/standard"
+	state2 "github.com/umbracle/greenhouse/internal/state"
+)
+
+// deployCode returns the init code that deploys the runtime code
+func deployCode(runtime string) string {
+	if len(runtime)/2 > 0xff {
+		return fmt.Sprintf("61%04x80600c6000396000f3", len(runtime)/2) + runtime
+	}
+	return fmt.Sprintf("60%02x80600b6000396000f3", len(runtime)/2) + runtime
+}
+
+// selector returns the selector of the method in hex
+func selector(method string) string {
+	return hex.EncodeToString(ethgo.Keccak256([]byte(method))[:4])
+}
+
+// dispatchCode returns the runtime code that runs the code of the
+// method with the selector of the calldata. The codes cannot jump.
+func dispatchCode(methods map[string]string) string {
+	names := []string{}
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// the selector, a jump for each method and a stop
+	offset := 6 + 11*len(names) + 1
+	jumps, bodies := "", ""
+	for _, name := range names {
+		jumps += "8063" + selector(name) + "14" + fmt.Sprintf("61%04x", offset) + "57"
+		body := "5b" + methods[name]
+		bodies += body
+		offset += len(body) / 2
+	}
+	return "60003560e01c" + jumps + "00" + bodies
+}
+
+func TestProject_TestIsolation(t *testing.T) {
+	// setUp writes 1 in the slot 0 and the tests revert if the slot
+	// is not 1 and write 2 in the slot 0
+	runtime := "60003560e01c" +
+		"8063" + selector("setUp()") + "14602557" +
+		"8063" + selector("testA()") + "14602c57" +
+		"8063" + selector("testB()") + "14602c57" +
+		"00" +
+		"5b600160005500" +
+		"5b600054600114603a576000" + "80fd" +
+		"5b600260005500"
+
+	// setUp reverts
+	reverts := "60006000fd"
+
+	abi := `[
+		{"type": "function", "name": "setUp", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testA", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testB", "inputs": [], "outputs": []}
+	]`
+
+	s, err := state2.NewState()
+	assert.NoError(t, err)
+
+	contracts := []*state2.Contract{
+		{Dir: "contracts", Filename: "A.sol", Name: "TestA", Abi: abi, Bin: deployCode(runtime), BinRuntime: runtime},
+		{Dir: "contracts", Filename: "B.sol", Name: "TestB", Abi: abi, Bin: deployCode(reverts), BinRuntime: reverts},
+	}
+	for _, contract := range contracts {
+		assert.NoError(t, s.UpsertContract(contract))
+	}
+	p := &Project{state: s, libDirectory: "lib"}
+
+	outputs, err := p.runTests(&TestInput{})
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 3)
+
+	// the tests do not see the changes of the previous tests
+	assert.Equal(t, "TestA", outputs[0].Contract)
+	assert.Equal(t, "testA", outputs[0].Method)
+	assert.True(t, outputs[0].Success)
+
+	assert.Equal(t, "TestA", outputs[1].Contract)
+	assert.Equal(t, "testB", outputs[1].Method)
+	assert.True(t, outputs[1].Success)
+
+	// a failed setUp fails the contract
+	assert.Equal(t, "TestB", outputs[2].Contract)
+	assert.Equal(t, "setUp", outputs[2].Method)
+	assert.False(t, outputs[2].Success)
+}
+
+// requireCode returns the code that reverts if the value on
+// top of the stack is zero. It jumps relative to its position.
+func requireCode() string {
+	return "58600a0157" + "60006000fd" + "5b"
+}
+
+func TestProject_SetUpSnapshot(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	revertTo := ethgo.Keccak256([]byte("revertTo(uint256)"))[:4]
+
+	// setUp writes 1 in the slot 0, takes a snapshot, stores its id in
+	// the slot 1 and writes 2 in the slot 0
+	setUp := "6001600055" + callCode(vmAddr, encodeVMCall(t, "snapshot()"), 0x0) + "600051600155" + "6002600055" + "00"
+
+	// the tests write 3 in the slot 0, revert to the snapshot of setUp
+	// and check that it succeeds and the slot 0 is 1
+	test := "6003600055" +
+		"7f" + hex.EncodeToString(revertTo) + strings.Repeat("00", 28) + "61010052" +
+		"600154" + "61010452" +
+		"6020" + "6000" + "6024" + "610100" + "6000" + "73" + hex.EncodeToString(vmAddr[:]) + "5af150" +
+		"600051" + requireCode() +
+		"600054600114" + requireCode() + "00"
+
+	runtime := dispatchCode(map[string]string{
+		"setUp()": setUp,
+		"testA()": test,
+		"testB()": test,
+	})
+	abi := `[
+		{"type": "function", "name": "setUp", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testA", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testB", "inputs": [], "outputs": []}
+	]`
+
+	s, err := state2.NewState()
+	assert.NoError(t, err)
+	assert.NoError(t, s.UpsertContract(&state2.Contract{Dir: "contracts", Filename: "A.sol", Name: "TestA", Abi: abi, Bin: deployCode(runtime), BinRuntime: runtime}))
+	p := &Project{state: s, libDirectory: "lib"}
+
+	// every test can revert to the snapshots of setUp
+	outputs, err := p.runTests(&TestInput{})
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 2)
+	for _, output := range outputs {
+		assert.True(t, output.Success, output.Method)
+	}
+}
+
+func TestProject_TestFail(t *testing.T) {
+	insufficient := append(ethgo.Keccak256([]byte("Insufficient(uint256)"))[:4], make([]byte, 32)...)
+	insufficient[35] = 0x1
+
+	runtime := dispatchCode(map[string]string{
+		"testFailRevert()":  revertCode(nil),
+		"testFailSuccess()": "00",
+		"testPanic()":       revertCode(append([]byte{0x4e, 0x48, 0x7b, 0x71}, append(make([]byte, 31), 0x11)...)),
+		"testError()":       revertCode(insufficient),
+	})
+	abi := `[
+		{"type": "function", "name": "testFailRevert", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testFailSuccess", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testPanic", "inputs": [], "outputs": []},
+		{"type": "function", "name": "testError", "inputs": [], "outputs": []}
+	]`
+
+	// the error is declared in another contract of the project
+	tokenABI := `[
+		{"type": "error", "name": "Insufficient", "inputs": [{"name": "available", "type": "uint256"}]}
+	]`
+
+	s, err := state2.NewState()
+	assert.NoError(t, err)
+
+	contracts := []*state2.Contract{
+		{Dir: "contracts", Filename: "A.sol", Name: "TestA", Abi: abi, Bin: deployCode(runtime), BinRuntime: runtime},
+		{Dir: "contracts", Filename: "Token.sol", Name: "Token", Abi: tokenABI},
+	}
+	for _, contract := range contracts {
+		assert.NoError(t, s.UpsertContract(contract))
+	}
+	p := &Project{state: s, libDirectory: "lib"}
+
+	outputs, err := p.runTests(&TestInput{})
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 4)
+
+	results := map[string]*TestOutput{}
+	for _, output := range outputs {
+		results[output.Method] = output
+	}
+
+	assert.True(t, results["testFailRevert"].Success)
+	assert.Empty(t, results["testFailRevert"].Reason)
+
+	assert.False(t, results["testFailSuccess"].Success)
+	assert.Equal(t, "testFail: expected the test to fail, but it succeeded", results["testFailSuccess"].Reason)
+
+	assert.False(t, results["testPanic"].Success)
+	assert.Equal(t, "Panic(0x11): arithmetic underflow or overflow", results["testPanic"].Reason)
+
+	assert.False(t, results["testError"].Success)
+	assert.Equal(t, "Insufficient(available: 1)", results["testError"].Reason)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/umbracle/ethgo/abi"
+)
+
+// panicSelector is the selector of Panic(uint256)
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+var panicType = abi.MustNewType("tuple(uint256)")
+
+// panicReasons are the meanings of the codes of Panic(uint256)
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "conversion to an invalid enum value",
+	0x22: "incorrectly encoded storage byte array",
+	0x31: "pop on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to a zero-initialized internal function",
+}
+
+// decodeRevert returns a human readable representation of the
+// return value of a reverted call
+func decodeRevert(data []byte) string {
+	return decodeRevertWithErrors(data, nil)
+}
+
+// decodeRevertWithErrors returns a human readable representation of the return
+// value of a reverted call with the custom errors indexed by selector
+func decodeRevertWithErrors(data []byte, errors map[string]*abi.Error) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevertError(data); err == nil {
+		return reason
+	}
+	if reason, ok := decodePanic(data); ok {
+		return reason
+	}
+	if len(data) >= 4 {
+		if e, ok := errors[hex.EncodeToString(data[:4])]; ok {
+			if reason, ok := decodeCustomError(e, data[4:]); ok {
+				return reason
+			}
+		}
+	}
+	return "0x" + hex.EncodeToString(data)
+}
+
+// decodePanic decodes the data of Panic(uint256) (i.e. Panic(0x11): arithmetic underflow or overflow)
+func decodePanic(data []byte) (string, bool) {
+	if !bytes.HasPrefix(data, panicSelector) {
+		return "", false
+	}
+	raw, err := abi.Decode(panicType, data[4:])
+	if err != nil {
+		return "", false
+	}
+	code := raw.(map[string]interface{})["0"].(*big.Int)
+
+	reason := fmt.Sprintf("Panic(0x%02x)", code)
+	if code.IsUint64() {
+		if meaning, ok := panicReasons[code.Uint64()]; ok {
+			reason += ": " + meaning
+		}
+	}
+	return reason, true
+}
+
+// decodeCustomError decodes the arguments of a custom error
+// (i.e. InsufficientBalance(available: 1, required: 2))
+func decodeCustomError(e *abi.Error, data []byte) (string, bool) {
+	elems := e.Inputs.TupleElems()
+	if len(elems) == 0 {
+		return e.Name + "()", true
+	}
+	raw, err := abi.Decode(e.Inputs, data)
+	if err != nil {
+		return "", false
+	}
+	obj := raw.(map[string]interface{})
+
+	args := []string{}
+	for indx, elem := range elems {
+		name := elem.Name
+		if name == "" {
+			name = strconv.Itoa(indx)
+		}
+		args = append(args, name+": "+formatValue(obj[name]))
+	}
+	return e.Name + "(" + strings.Join(args, ", ") + ")", true
+}
+
+// errorSelector returns the selector of a custom error in hex
+func errorSelector(e *abi.Error) string {
+	method := &abi.Method{Name: e.Name, Inputs: e.Inputs}
+	return hex.EncodeToString(method.ID())
+}
+
+var revertErrorType = abi.MustNewType("tuple(string)")
+
+// encodeRevert returns the return value of a call that
+// reverts with the reason (i.e. revert(reason))
+func encodeRevert(reason string) []byte {
+	data, err := abi.Encode([]interface{}{reason}, revertErrorType)
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte{0x08, 0xc3, 0x79, 0xa0}, data...)
+}
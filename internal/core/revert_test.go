@@ -0,0 +1,52 @@
+package core
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	data, err := abi.MustNewType("tuple(string)").Encode([]interface{}{"reason"})
+	assert.NoError(t, err)
+	data = append([]byte{0x8, 0xC3, 0x79, 0xA0}, data...)
+
+	assert.Equal(t, "reason", decodeRevert(data))
+	assert.Equal(t, "", decodeRevert(nil))
+	assert.Equal(t, "0x"+hex.EncodeToString([]byte{1, 2}), decodeRevert([]byte{1, 2}))
+}
+
+func TestDecodeRevert_Panic(t *testing.T) {
+	panicData := func(code uint64) []byte {
+		data := append([]byte{0x4e, 0x48, 0x7b, 0x71}, make([]byte, 32)...)
+		data[35] = byte(code)
+		return data
+	}
+	assert.Equal(t, "Panic(0x01): assertion failed", decodeRevert(panicData(0x01)))
+	assert.Equal(t, "Panic(0x12): division or modulo by zero", decodeRevert(panicData(0x12)))
+	assert.Equal(t, "Panic(0x99)", decodeRevert(panicData(0x99)))
+}
+
+func TestDecodeRevert_CustomError(t *testing.T) {
+	errors := map[string]*abi.Error{}
+	for _, sig := range []string{"error Unauthorized()", "error Insufficient(uint256 available, address)"} {
+		e, err := abi.NewError(sig)
+		assert.NoError(t, err)
+		errors[errorSelector(e)] = e
+	}
+
+	unauthorized := ethgo.Keccak256([]byte("Unauthorized()"))[:4]
+	assert.Equal(t, "Unauthorized()", decodeRevertWithErrors(unauthorized, errors))
+
+	data, err := abi.MustNewType("tuple(uint256,address)").Encode([]interface{}{big.NewInt(1), ethgo.Address{0x1}})
+	assert.NoError(t, err)
+	insufficient := append(ethgo.Keccak256([]byte("Insufficient(uint256,address)"))[:4], data...)
+	assert.Equal(t, "Insufficient(available: 1, 1: 0x0100000000000000000000000000000000000000)", decodeRevertWithErrors(insufficient, errors))
+
+	// the errors that are not known are shown in hex
+	assert.Equal(t, "0x"+hex.EncodeToString(unauthorized), decodeRevert(unauthorized))
+}
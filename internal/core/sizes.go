@@ -0,0 +1,79 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+const (
+	// MaxCodeSize is the maximum size of the runtime code (EIP-170)
+	MaxCodeSize = 24576
+
+	// MaxInitCodeSize is the maximum size of the init code (EIP-3860)
+	MaxInitCodeSize = 2 * MaxCodeSize
+)
+
+// ContractSize is the size in bytes of the code of a contract
+type ContractSize struct {
+	Name     string
+	Source   string
+	Runtime  int
+	Initcode int
+}
+
+// RuntimeMargin returns the bytes left until the runtime code limit.
+// It is negative if the contract cannot be deployed.
+func (c *ContractSize) RuntimeMargin() int {
+	return MaxCodeSize - c.Runtime
+}
+
+// InitcodeMargin returns the bytes left until the init code limit
+func (c *ContractSize) InitcodeMargin() int {
+	return MaxInitCodeSize - c.Initcode
+}
+
+// Oversized returns whether any of the codes is over its limit
+func (c *ContractSize) Oversized() bool {
+	return c.RuntimeMargin() < 0 || c.InitcodeMargin() < 0
+}
+
+func newContractSize(contract *state.Contract) *ContractSize {
+	// the placeholders of the unlinked libraries have the same
+	// length as the address so the size is still exact
+	return &ContractSize{
+		Name:     contract.Name,
+		Source:   contract.Dir + "/" + contract.Filename,
+		Runtime:  len(strings.TrimPrefix(contract.BinRuntime, "0x")) / 2,
+		Initcode: len(strings.TrimPrefix(contract.Bin, "0x")) / 2,
+	}
+}
+
+// Sizes returns the code sizes of the deployable contracts of the project
+// sorted by name. Test contracts and contracts without code (i.e. interfaces)
+// are not included.
+func (p *Project) Sizes() ([]*ContractSize, error) {
+	contracts, err := p.Contracts()
+	if err != nil {
+		return nil, err
+	}
+	return contractSizes(contracts), nil
+}
+
+func contractSizes(contracts []*state.Contract) []*ContractSize {
+	sizes := []*ContractSize{}
+	for _, contract := range contracts {
+		if contract.Bin == "" || strings.HasPrefix(contract.Name, "Test") {
+			continue
+		}
+		sizes = append(sizes, newContractSize(contract))
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Name != sizes[j].Name {
+			return sizes[i].Name < sizes[j].Name
+		}
+		return sizes[i].Source < sizes[j].Source
+	})
+	return sizes
+}
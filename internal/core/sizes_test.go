@@ -0,0 +1,30 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestContractSizes(t *testing.T) {
+	contracts := []*state.Contract{
+		{Name: "B", Bin: "600060", BinRuntime: "60"},
+		{Name: "A", Bin: strings.Repeat("00", MaxInitCodeSize), BinRuntime: strings.Repeat("00", MaxCodeSize+1)},
+		{Name: "IA"},
+		{Name: "TestA", Bin: "00", BinRuntime: "00"},
+	}
+	sizes := contractSizes(contracts)
+	assert.Len(t, sizes, 2)
+
+	assert.Equal(t, "A", sizes[0].Name)
+	assert.Equal(t, -1, sizes[0].RuntimeMargin())
+	assert.Equal(t, 0, sizes[0].InitcodeMargin())
+	assert.True(t, sizes[0].Oversized())
+
+	assert.Equal(t, "B", sizes[1].Name)
+	assert.Equal(t, 1, sizes[1].Runtime)
+	assert.Equal(t, 3, sizes[1].Initcode)
+	assert.False(t, sizes[1].Oversized())
+}
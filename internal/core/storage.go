@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/umbracle/greenhouse/internal/solidity"
+)
+
+// StorageSlot is a state variable (or a member of a struct state variable)
+// in the storage of a contract
+type StorageSlot struct {
+	// Name is the name of the variable. Struct members have the format <var>.<member>
+	Name string `json:"name"`
+
+	// Contract is the contract that declares the variable
+	Contract string `json:"contract"`
+
+	// Slot is the storage slot in decimal
+	Slot string `json:"slot"`
+
+	// Offset is the byte offset inside the slot
+	Offset int `json:"offset"`
+
+	// Bytes is the number of bytes used by the variable
+	Bytes int `json:"bytes"`
+
+	// Type is the Solidity type of the variable
+	Type string `json:"type"`
+}
+
+// StorageLayout returns the storage layout of the contract, including
+// the inherited variables, in storage order
+func (p *Project) StorageLayout(name string) ([]*StorageSlot, error) {
+	contract, err := p.Contract(name)
+	if err != nil {
+		return nil, err
+	}
+	if contract.StorageLayout == nil {
+		return nil, fmt.Errorf("storage layout of '%s' not found, build the project with solidity 0.5.13 or greater", name)
+	}
+	return storageSlots(contract.StorageLayout)
+}
+
+func storageSlots(layout *solidity.StorageLayout) ([]*StorageSlot, error) {
+	slots := []*StorageSlot{}
+	if err := appendStorageSlots(&slots, layout, layout.Storage, "", big.NewInt(0)); err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// appendStorageSlots appends the entries (relative to the base slot) and
+// the members of the struct entries
+func appendStorageSlots(slots *[]*StorageSlot, layout *solidity.StorageLayout, entries []*solidity.StorageEntry, prefix string, base *big.Int) error {
+	for _, entry := range entries {
+		typ, ok := layout.Types[entry.Type]
+		if !ok {
+			return fmt.Errorf("type '%s' not found in the storage layout", entry.Type)
+		}
+		slot, ok := new(big.Int).SetString(entry.Slot, 10)
+		if !ok {
+			return fmt.Errorf("incorrect slot '%s' for '%s'", entry.Slot, entry.Label)
+		}
+		slot.Add(slot, base)
+
+		size, err := strconv.Atoi(typ.NumberOfBytes)
+		if err != nil {
+			return fmt.Errorf("incorrect size '%s' for '%s'", typ.NumberOfBytes, entry.Label)
+		}
+
+		name := prefix + entry.Label
+		*slots = append(*slots, &StorageSlot{
+			Name:     name,
+			Contract: entry.Contract,
+			Slot:     slot.String(),
+			Offset:   entry.Offset,
+			Bytes:    size,
+			Type:     typ.Label,
+		})
+
+		// the members of an inplace struct are stored from its slot
+		if typ.Encoding == "inplace" && len(typ.Members) != 0 {
+			if err := appendStorageSlots(slots, layout, typ.Members, name+".", slot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
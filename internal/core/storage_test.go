@@ -0,0 +1,55 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/solidity"
+)
+
+func TestStorageSlots(t *testing.T) {
+	// storage layout of:
+	// contract Base { uint128 a; bool b; }
+	// contract A is Base { struct S { uint256 x; address y; } mapping(address => uint256) m; S s; }
+	raw := `{
+		"storage": [
+			{"astId": 1, "contract": "A.sol:Base", "label": "a", "offset": 0, "slot": "0", "type": "t_uint128"},
+			{"astId": 2, "contract": "A.sol:Base", "label": "b", "offset": 16, "slot": "0", "type": "t_bool"},
+			{"astId": 3, "contract": "A.sol:A", "label": "m", "offset": 0, "slot": "1", "type": "t_mapping(t_address,t_uint256)"},
+			{"astId": 4, "contract": "A.sol:A", "label": "s", "offset": 0, "slot": "2", "type": "t_struct(S)10_storage"}
+		],
+		"types": {
+			"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+			"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+			"t_uint128": {"encoding": "inplace", "label": "uint128", "numberOfBytes": "16"},
+			"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+			"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+			"t_struct(S)10_storage": {"encoding": "inplace", "label": "struct A.S", "numberOfBytes": "64", "members": [
+				{"astId": 5, "contract": "A.sol:A", "label": "x", "offset": 0, "slot": "0", "type": "t_uint256"},
+				{"astId": 6, "contract": "A.sol:A", "label": "y", "offset": 0, "slot": "1", "type": "t_address"}
+			]}
+		}
+	}`
+
+	// the layout is encoded as a string before solidity 0.8.0
+	data, err := json.Marshal(raw)
+	assert.NoError(t, err)
+
+	for _, input := range []string{raw, string(data)} {
+		var layout *solidity.StorageLayout
+		assert.NoError(t, json.Unmarshal([]byte(input), &layout))
+
+		slots, err := storageSlots(layout)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []*StorageSlot{
+			{Name: "a", Contract: "A.sol:Base", Slot: "0", Offset: 0, Bytes: 16, Type: "uint128"},
+			{Name: "b", Contract: "A.sol:Base", Slot: "0", Offset: 16, Bytes: 1, Type: "bool"},
+			{Name: "m", Contract: "A.sol:A", Slot: "1", Offset: 0, Bytes: 32, Type: "mapping(address => uint256)"},
+			{Name: "s", Contract: "A.sol:A", Slot: "2", Offset: 0, Bytes: 64, Type: "struct A.S"},
+			{Name: "s.x", Contract: "A.sol:A", Slot: "2", Offset: 0, Bytes: 32, Type: "uint256"},
+			{Name: "s.y", Contract: "A.sol:A", Slot: "3", Offset: 0, Bytes: 20, Type: "address"},
+		}, slots)
+	}
+}
@@ -0,0 +1,408 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+	"github.com/umbracle/greenhouse/internal/standard"
+)
+
+// vmHandler runs a cheatcode of greenhouse/Vm.sol with the decoded
+// arguments and returns the values of the outputs
+type vmHandler func(ctx *state.CallContext, args []interface{}) ([]interface{}, error)
+
+// vmCheatcode runs the cheatcodes of greenhouse/Vm.sol
+type vmCheatcode struct {
+	handlers map[string]vmHandler
+
+	// project resolves the contracts of getCode
+	project *Project
+
+	// prank is the active prank if any
+	prank *prank
+
+	// pranked are the running calls with a prank
+	pranked []*prankedCall
+
+	// expectedRevert is the revert expected for the next call if any
+	expectedRevert *expectedRevert
+
+	// expectedEmits are the logs expected in the next call
+	expectedEmits []*expectedEmit
+
+	// emitted are the logs emitted during the calls that expect logs
+	emitted []*state.Log
+
+	// emitFrames are the positions in emitted of the first log of the running calls
+	emitFrames []int
+
+	// expectedCalls are the calls expected in the next call
+	expectedCalls []*expectedCall
+
+	// mockedCalls are the mocked calls by callee
+	mockedCalls map[evmc.Address][]*mockedCall
+
+	// snapshots are the block environments of the snapshots by id
+	snapshots map[int]state.TxContext
+
+	// accesses are the recorded storage accesses by account, nil if
+	// the accesses are not being recorded
+	accesses map[evmc.Address]*storageAccesses
+
+	// recordedLogs are the recorded logs, nil if the logs are not being recorded
+	recordedLogs []*state.Log
+
+	// readLines are the next lines to read with readLine by path
+	readLines map[string]int
+
+	// events are the events of the project by id
+	events map[ethgo.Hash]*abi.Event
+
+	// failures are the expectations of the test that were not met
+	failures []string
+}
+
+func newVMCheatcode(project *Project) *vmCheatcode {
+	v := &vmCheatcode{
+		project: project,
+	}
+	v.handlers = map[string]vmHandler{
+		"warp(uint256)":       v.warp,
+		"roll(uint256)":       v.roll,
+		"fee(uint256)":        v.fee,
+		"chainId(uint256)":    v.chainID,
+		"coinbase(address)":   v.coinbase,
+		"difficulty(uint256)": v.difficulty,
+		"prevrandao(bytes32)": v.prevrandao,
+		"txGasPrice(uint256)": v.txGasPrice,
+
+		"prank(address)":              v.prankCall,
+		"prank(address,address)":      v.prankCall,
+		"startPrank(address)":         v.startPrank,
+		"startPrank(address,address)": v.startPrank,
+		"stopPrank()":                 v.stopPrank,
+
+		"deal(address,uint256)":          v.deal,
+		"store(address,bytes32,bytes32)": v.store,
+		"load(address,bytes32)":          v.load,
+		"etch(address,bytes)":            v.etch,
+		"setNonce(address,uint64)":       v.setNonce,
+		"getNonce(address)":              v.getNonce,
+		"getCode(string)":                v.getCode,
+		"getDeployedCode(string)":        v.getDeployedCode,
+
+		"expectRevert()":       v.expectRevert,
+		"expectRevert(bytes4)": v.expectRevert,
+		"expectRevert(bytes)":  v.expectRevert,
+
+		"expectEmit(bool,bool,bool,bool)":         v.expectEmit,
+		"expectEmit(bool,bool,bool,bool,address)": v.expectEmit,
+
+		"expectCall(address,bytes)":                v.expectCall,
+		"expectCall(address,uint256,bytes)":        v.expectCall,
+		"expectCall(address,bytes,uint64)":         v.expectCall,
+		"expectCall(address,uint256,bytes,uint64)": v.expectCall,
+
+		"mockCall(address,bytes,bytes)":       v.mockCall,
+		"mockCallRevert(address,bytes,bytes)": v.mockCallRevert,
+		"clearMockedCalls()":                  v.clearMockedCalls,
+
+		"snapshot()":        v.snapshot,
+		"revertTo(uint256)": v.revertTo,
+
+		"record()":          v.record,
+		"accesses(address)": v.getAccesses,
+		"recordLogs()":      v.recordLogs,
+		"getRecordedLogs()": v.getRecordedLogs,
+
+		"envUint(string)":          v.env(new(big.Int)),
+		"envAddress(string)":       v.env(ethgo.Address{}),
+		"envString(string)":        v.env(""),
+		"envOr(string,uint256)":    v.envOr,
+		"envOr(string,address)":    v.envOr,
+		"envOr(string,string)":     v.envOr,
+		"readFile(string)":         v.readFile,
+		"readLine(string)":         v.readLine,
+		"writeFile(string,string)": v.writeFile,
+		"ffi(string[])":            v.ffi,
+
+		"sign(uint256,bytes32)":                          v.sign,
+		"addr(uint256)":                                  v.addr,
+		"deriveKey(string,uint32)":                       v.deriveKey,
+		"domainSeparator(string,string,uint256,address)": v.domainSeparator,
+		"hashTypedData(bytes32,bytes32)":                 v.hashTypedData,
+	}
+	return v
+}
+
+// reset clears the state of the cheatcodes that only applies to a single test
+func (v *vmCheatcode) reset() {
+	v.prank = nil
+	v.pranked = nil
+	v.expectedRevert = nil
+	v.expectedEmits = nil
+	v.emitted = nil
+	v.emitFrames = nil
+	v.expectedCalls = nil
+	v.mockedCalls = nil
+	v.snapshots = nil
+	v.accesses = nil
+	v.recordedLogs = nil
+	v.readLines = nil
+	v.failures = []string{}
+}
+
+// setUpState is the state of the cheatcodes set in setUp that
+// applies to every test of the contract
+type setUpState struct {
+	prank       *prank
+	mockedCalls map[evmc.Address][]*mockedCall
+	snapshots   map[int]state.TxContext
+}
+
+// saveSetUp returns the state of the cheatcodes set in setUp
+func (v *vmCheatcode) saveSetUp() *setUpState {
+	s := &setUpState{
+		mockedCalls: map[evmc.Address][]*mockedCall{},
+		snapshots:   map[int]state.TxContext{},
+	}
+	if v.prank != nil {
+		prank := *v.prank
+		s.prank = &prank
+	}
+	for callee, mocks := range v.mockedCalls {
+		s.mockedCalls[callee] = mocks
+	}
+	// the snapshots taken before the state of setUp are still valid
+	for id, env := range v.snapshots {
+		s.snapshots[id] = env
+	}
+	return s
+}
+
+// restoreSetUp resets the cheatcodes to the state after setUp
+func (v *vmCheatcode) restoreSetUp(s *setUpState) {
+	v.reset()
+	if s.prank != nil {
+		prank := *s.prank
+		v.prank = &prank
+	}
+	if len(s.mockedCalls) != 0 {
+		v.mockedCalls = map[evmc.Address][]*mockedCall{}
+		for callee, mocks := range s.mockedCalls {
+			v.mockedCalls[callee] = mocks
+		}
+	}
+	if len(s.snapshots) != 0 {
+		v.snapshots = map[int]state.TxContext{}
+		for id, env := range s.snapshots {
+			v.snapshots[id] = env
+		}
+	}
+}
+
+// finish checks the expectations that are still pending at
+// the end of the test and returns the ones that were not met
+func (v *vmCheatcode) finish() []string {
+	if v.expectedRevert != nil && v.expectedRevert.contract == nil {
+		v.failures = append(v.failures, "expectRevert: expected a revert, but no call was made")
+	}
+	for _, e := range v.expectedEmits {
+		if e.log == nil {
+			v.failures = append(v.failures, "expectEmit: expected an event emitted by the test, but none was emitted")
+		} else {
+			v.failures = append(v.failures, fmt.Sprintf("expectEmit: expected %s, but no call was made", v.formatLog(e.log)))
+		}
+	}
+	for _, e := range v.expectedCalls {
+		if !e.met() {
+			v.failures = append(v.failures, "expectCall: "+e.describe())
+		}
+	}
+	return v.failures
+}
+
+func (v *vmCheatcode) BeforeCall(t *state.Transition, c *state.Contract) {
+	// match the expectations before the prank changes the caller
+	v.beginExpectRevert(c)
+	v.beginExpectEmit(c)
+	v.beginExpectCall(c)
+	v.beginEmitFrame()
+	v.beginPrank(t, c)
+	v.recordExpectCall(c)
+}
+
+func (v *vmCheatcode) AfterCall(t *state.Transition, c *state.Contract, output []byte, err error) ([]byte, error) {
+	v.endPrank(t, c)
+	v.endExpectCall(c)
+	// the logs of a reverted call are discarded before the expected logs are matched
+	v.endEmitFrame(err != nil)
+	output, err = v.endExpectEmit(c, output, err)
+	return v.endExpectRevert(c, output, err)
+}
+
+func (v *vmCheatcode) OnLog(t *state.Transition, log *state.Log) bool {
+	if !v.matchExpectEmit(log) {
+		return false
+	}
+	if v.recordedLogs != nil {
+		v.recordedLogs = append(v.recordedLogs, log)
+	}
+	return true
+}
+
+func (v *vmCheatcode) CanRun(addr evmc.Address) bool {
+	return ethgo.Address(addr) == standard.VMAddress
+}
+
+func (v *vmCheatcode) Run(ctx *state.CallContext, input []byte) ([]byte, uint64, error) {
+	if len(input) < 4 {
+		return encodeRevert("vm: input too short"), 0, state.ErrExecutionReverted
+	}
+	method, ok := standard.VMMethods[hex.EncodeToString(input[:4])]
+	if !ok {
+		return encodeRevert(fmt.Sprintf("vm: unknown cheatcode 0x%s", hex.EncodeToString(input[:4]))), 0, state.ErrExecutionReverted
+	}
+	handler, ok := v.handlers[method.Sig()]
+	if !ok {
+		return encodeRevert(fmt.Sprintf("vm: %s is not implemented", method.Sig())), 0, state.ErrExecutionReverted
+	}
+
+	args := []interface{}{}
+	if elems := method.Inputs.TupleElems(); len(elems) != 0 {
+		raw, err := abi.Decode(method.Inputs, input[4:])
+		if err != nil {
+			return encodeRevert(fmt.Sprintf("vm: %s: failed to decode: %v", method.Name, err)), 0, state.ErrExecutionReverted
+		}
+		obj := raw.(map[string]interface{})
+		for indx, elem := range elems {
+			name := elem.Name
+			if name == "" {
+				name = strconv.Itoa(indx)
+			}
+			args = append(args, obj[name])
+		}
+	}
+
+	res, err := handler(ctx, args)
+	if err != nil {
+		return encodeRevert(fmt.Sprintf("vm: %s: %v", method.Name, err)), 0, state.ErrExecutionReverted
+	}
+	output, err := abi.Encode(res, method.Outputs)
+	if err != nil {
+		return encodeRevert(fmt.Sprintf("vm: %s: failed to encode: %v", method.Name, err)), 0, state.ErrExecutionReverted
+	}
+	return output, 0, nil
+}
+
+// toInt64 converts an uint256 argument to the int64 values of the context
+func toInt64(val *big.Int) (int64, error) {
+	if !val.IsInt64() {
+		return 0, fmt.Errorf("value %s out of range", val)
+	}
+	return val.Int64(), nil
+}
+
+// toHash converts an uint256 argument to its 32 bytes representation
+func toHash(val *big.Int) (res evmc.Hash) {
+	val.FillBytes(res[:])
+	return
+}
+
+func (v *vmCheatcode) warp(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	timestamp, err := toInt64(args[0].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Transition.Context().Timestamp = timestamp
+	return nil, nil
+}
+
+func (v *vmCheatcode) roll(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	number, err := toInt64(args[0].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Transition.Context().Number = number
+	return nil, nil
+}
+
+func (v *vmCheatcode) fee(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Context().BaseFee = toHash(args[0].(*big.Int))
+	return nil, nil
+}
+
+func (v *vmCheatcode) chainID(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	chainID, err := toInt64(args[0].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Transition.Context().ChainID = chainID
+	return nil, nil
+}
+
+func (v *vmCheatcode) coinbase(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Context().Coinbase = evmc.Address(args[0].(ethgo.Address))
+	return nil, nil
+}
+
+func (v *vmCheatcode) difficulty(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Context().Difficulty = toHash(args[0].(*big.Int))
+	return nil, nil
+}
+
+func (v *vmCheatcode) prevrandao(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Context().Difficulty = evmc.Hash(args[0].([32]byte))
+	return nil, nil
+}
+
+func (v *vmCheatcode) snapshot(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	id := ctx.Transition.Txn().Snapshot()
+	if v.snapshots == nil {
+		v.snapshots = map[int]state.TxContext{}
+	}
+	v.snapshots[id] = *ctx.Transition.Context()
+	return []interface{}{big.NewInt(int64(id))}, nil
+}
+
+func (v *vmCheatcode) revertTo(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	id := args[0].(*big.Int)
+	if !id.IsInt64() {
+		return []interface{}{false}, nil
+	}
+	env, ok := v.snapshots[int(id.Int64())]
+	if !ok {
+		return []interface{}{false}, nil
+	}
+	// the snapshots of the calls in progress are kept since a call
+	// that started after the snapshot can still revert
+	if err := ctx.Transition.Txn().RestoreSnapshot(int(id.Int64())); err != nil {
+		return []interface{}{false}, nil
+	}
+
+	// the snapshots taken after it with the cheatcode are discarded
+	for snapshot := range v.snapshots {
+		if snapshot > int(id.Int64()) {
+			delete(v.snapshots, snapshot)
+		}
+	}
+
+	// the fields of the transaction are not part of the snapshot
+	current := ctx.Transition.Context()
+	env.Hash = current.Hash
+	env.Origin = current.Origin
+	env.GasPrice = current.GasPrice
+	*current = env
+	return []interface{}{true}, nil
+}
+
+func (v *vmCheatcode) txGasPrice(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Context().GasPrice = toHash(args[0].(*big.Int))
+	return nil, nil
+}
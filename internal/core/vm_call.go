@@ -0,0 +1,140 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// expectedCall is a call expected during the next call of a contract
+type expectedCall struct {
+	// caller is the contract that expects the call
+	caller evmc.Address
+
+	// depth is the depth of the calls made by the caller
+	depth int
+
+	// contract is the call during which the calls are recorded
+	contract *state.Contract
+
+	// done signals whether the call that records the calls ended
+	done bool
+
+	// callee is the address of the expected call
+	callee evmc.Address
+
+	// data is the prefix of the calldata of the expected call
+	data []byte
+
+	// value is the value of the expected call if set
+	value *big.Int
+
+	// count is the exact number of expected calls if set
+	count *uint64
+
+	// calls is the number of calls that match
+	calls uint64
+}
+
+// match returns whether the call matches the expected call
+func (e *expectedCall) match(c *state.Contract) bool {
+	if c.CodeAddress != e.callee {
+		return false
+	}
+	if !bytes.HasPrefix(c.Input, e.data) {
+		return false
+	}
+	if e.value != nil {
+		value := c.Value
+		if value == nil {
+			value = big.NewInt(0)
+		}
+		if value.Cmp(e.value) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// met returns whether the calls made meet the expectation
+func (e *expectedCall) met() bool {
+	if e.count != nil {
+		return e.calls == *e.count
+	}
+	return e.calls != 0
+}
+
+// describe returns a description of the unmet expectation
+func (e *expectedCall) describe() string {
+	call := fmt.Sprintf("to %s with data 0x%s", ethgo.Address(e.callee), hex.EncodeToString(e.data))
+	if e.value != nil {
+		call += fmt.Sprintf(" and value %s", e.value)
+	}
+	if e.count != nil {
+		return fmt.Sprintf("expected %d calls %s, got %d", *e.count, call, e.calls)
+	}
+	return fmt.Sprintf("expected a call %s, got none", call)
+}
+
+func (v *vmCheatcode) expectCall(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	e := &expectedCall{
+		caller: ctx.Caller,
+		depth:  ctx.Depth,
+		callee: evmc.Address(args[0].(ethgo.Address)),
+	}
+	args = args[1:]
+	if value, ok := args[0].(*big.Int); ok {
+		e.value = value
+		args = args[1:]
+	}
+	e.data = args[0].([]byte)
+	if len(args) > 1 {
+		count := args[1].(uint64)
+		e.count = &count
+	}
+	v.expectedCalls = append(v.expectedCalls, e)
+	return nil, nil
+}
+
+// beginExpectCall marks the call as the one that records the expected calls
+func (v *vmCheatcode) beginExpectCall(c *state.Contract) {
+	for _, e := range v.expectedCalls {
+		if e.contract != nil || e.done {
+			continue
+		}
+		if c.Depth != e.depth || c.Caller != e.caller {
+			continue
+		}
+		e.contract = c
+	}
+}
+
+// recordExpectCall counts the call for the expected calls it matches
+// if it is made during the call that records them
+func (v *vmCheatcode) recordExpectCall(c *state.Contract) {
+	if c.Type == evmc.Create || c.Type == evmc.Create2 {
+		return
+	}
+	for _, e := range v.expectedCalls {
+		if e.contract == nil || e.done {
+			continue
+		}
+		if e.match(c) {
+			e.calls++
+		}
+	}
+}
+
+// endExpectCall stops recording the expected calls of the call
+func (v *vmCheatcode) endExpectCall(c *state.Contract) {
+	for _, e := range v.expectedCalls {
+		if e.contract == c {
+			e.done = true
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/wallet"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// eip712DomainType is the type hash of the EIP-712 domain with the name,
+// version, chain id and verifying contract
+var eip712DomainType = ethgo.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+
+var eip712DomainArgs = abi.MustNewType("tuple(bytes32,bytes32,bytes32,uint256,address)")
+
+// privateKey returns the key of the private key argument
+func privateKey(val *big.Int) (*wallet.Key, error) {
+	if val.Sign() == 0 || val.Cmp(wallet.S256.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	buf := make([]byte, 32)
+	val.FillBytes(buf)
+	return wallet.NewWalletFromPrivKey(buf)
+}
+
+func (v *vmCheatcode) sign(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	key, err := privateKey(args[0].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	digest := args[1].([32]byte)
+	sig, err := key.Sign(digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature is r, s and the recovery id (0 or 1)
+	var r, s [32]byte
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return []interface{}{sig[64] + 27, r, s}, nil
+}
+
+func (v *vmCheatcode) addr(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	key, err := privateKey(args[0].(*big.Int))
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{key.Address()}, nil
+}
+
+func (v *vmCheatcode) deriveKey(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(args[0].(string), "")
+	if err != nil {
+		return nil, err
+	}
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	path := wallet.DerivationPath{0x80000000 + 44, 0x80000000 + 60, 0x80000000 + 0, 0, args[1].(uint32)}
+	priv, err := path.Derive(master)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{new(big.Int).Set(priv.D)}, nil
+}
+
+func (v *vmCheatcode) domainSeparator(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	var typ, name, version [32]byte
+	copy(typ[:], eip712DomainType)
+	copy(name[:], ethgo.Keccak256([]byte(args[0].(string))))
+	copy(version[:], ethgo.Keccak256([]byte(args[1].(string))))
+
+	data, err := abi.Encode([]interface{}{typ, name, version, args[2], args[3]}, eip712DomainArgs)
+	if err != nil {
+		return nil, err
+	}
+	var separator [32]byte
+	copy(separator[:], ethgo.Keccak256(data))
+	return []interface{}{separator}, nil
+}
+
+func (v *vmCheatcode) hashTypedData(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	separator, structHash := args[0].([32]byte), args[1].([32]byte)
+
+	var digest [32]byte
+	copy(digest[:], ethgo.Keccak256([]byte{0x19, 0x01}, separator[:], structHash[:]))
+	return []interface{}{digest}, nil
+}
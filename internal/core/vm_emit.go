@@ -0,0 +1,250 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// expectedEmit is a log expected in the next call of a contract
+type expectedEmit struct {
+	// caller is the contract that expects the log
+	caller evmc.Address
+
+	// depth is the depth of the calls made by the caller
+	depth int
+
+	// checks are the flags to check the topics 1 to 3 and the data
+	checks [4]bool
+
+	// emitter is the expected address of the log if set
+	emitter *evmc.Address
+
+	// log is the expected log emitted by the caller
+	log *state.Log
+
+	// contract is the call that is expected to emit the log
+	contract *state.Contract
+
+	// start is the position in the emitted logs of the first log of the call
+	start int
+}
+
+// match returns whether the log matches the expected log
+func (e *expectedEmit) match(log *state.Log) bool {
+	if e.emitter != nil && log.Address != *e.emitter {
+		return false
+	}
+	if len(log.Topics) != len(e.log.Topics) {
+		return false
+	}
+	for indx, topic := range log.Topics {
+		// the topic 0 is the id of the event and it is always checked
+		if indx != 0 && !e.checks[indx-1] {
+			continue
+		}
+		if topic != e.log.Topics[indx] {
+			return false
+		}
+	}
+	if e.checks[3] && !bytes.Equal(log.Data, e.log.Data) {
+		return false
+	}
+	return true
+}
+
+func (v *vmCheatcode) expectEmit(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	e := &expectedEmit{
+		caller: ctx.Caller,
+		depth:  ctx.Depth,
+	}
+	for i := 0; i < 4; i++ {
+		e.checks[i] = args[i].(bool)
+	}
+	if len(args) > 4 {
+		emitter := evmc.Address(args[4].(ethgo.Address))
+		e.emitter = &emitter
+	}
+	v.expectedEmits = append(v.expectedEmits, e)
+	return nil, nil
+}
+
+// beginExpectEmit marks the call as the one expected to emit the logs
+func (v *vmCheatcode) beginExpectEmit(c *state.Contract) {
+	for _, e := range v.expectedEmits {
+		if e.log == nil || e.contract != nil {
+			continue
+		}
+		if c.Depth != e.depth || c.Caller != e.caller {
+			continue
+		}
+		e.contract = c
+		e.start = len(v.emitted)
+	}
+}
+
+// matchExpectEmit records the log emitted by the test as the expected log or
+// as a log emitted during the expected calls. It returns false if the log
+// is the expected log, which is not part of the output of the test.
+func (v *vmCheatcode) matchExpectEmit(log *state.Log) bool {
+	active := false
+	for _, e := range v.expectedEmits {
+		if e.log == nil {
+			if e.contract == nil && log.Address == e.caller {
+				e.log = log
+				return false
+			}
+			continue
+		}
+		if e.contract != nil {
+			active = true
+		}
+	}
+	if active {
+		v.emitted = append(v.emitted, log)
+	}
+	return true
+}
+
+// beginEmitFrame records the position of the first log of a call
+func (v *vmCheatcode) beginEmitFrame() {
+	v.emitFrames = append(v.emitFrames, len(v.emitted))
+}
+
+// endEmitFrame discards the logs of the call if it reverts since
+// they are not part of the state anymore
+func (v *vmCheatcode) endEmitFrame(reverted bool) {
+	if len(v.emitFrames) == 0 {
+		return
+	}
+	start := v.emitFrames[len(v.emitFrames)-1]
+	v.emitFrames = v.emitFrames[:len(v.emitFrames)-1]
+	if reverted && start < len(v.emitted) {
+		v.emitted = v.emitted[:start]
+	}
+}
+
+// endExpectEmit checks that the call emitted the expected logs in order with
+// the logs that remain at the end of the call. Otherwise, it reverts with
+// the explanation.
+func (v *vmCheatcode) endExpectEmit(c *state.Contract, output []byte, err error) ([]byte, error) {
+	pending := []*expectedEmit{}
+	expected := []*expectedEmit{}
+	for _, e := range v.expectedEmits {
+		if e.contract != c {
+			pending = append(pending, e)
+		} else {
+			expected = append(expected, e)
+		}
+	}
+	v.expectedEmits = pending
+	if len(expected) == 0 {
+		return output, err
+	}
+
+	logs := []*state.Log{}
+	if start := expected[0].start; start < len(v.emitted) {
+		logs = v.emitted[start:]
+	}
+
+	// the expected logs are matched in order
+	indx := 0
+	for _, log := range logs {
+		if indx < len(expected) && expected[indx].match(log) {
+			indx++
+		}
+	}
+
+	if indx == len(expected) {
+		return output, err
+	}
+
+	got := "no events"
+	if len(logs) != 0 {
+		strs := []string{}
+		for _, log := range logs {
+			strs = append(strs, v.formatLog(log))
+		}
+		got = strings.Join(strs, ", ")
+	}
+	failures := []string{}
+	for _, e := range expected[indx:] {
+		failures = append(failures, fmt.Sprintf("expectEmit: expected %s, got %s", v.formatLog(e.log), got))
+	}
+	v.failures = append(v.failures, failures...)
+	return encodeRevert(failures[0]), state.ErrExecutionReverted
+}
+
+// formatLog formats the log with the events of the project
+func (v *vmCheatcode) formatLog(log *state.Log) string {
+	emitter := ethgo.Address(log.Address).String()
+	if len(log.Topics) != 0 {
+		if event, ok := v.projectEvents()[ethgo.Hash(log.Topics[0])]; ok {
+			if str, ok := formatEvent(event, log); ok {
+				return str + " from " + emitter
+			}
+		}
+	}
+
+	topics := []string{}
+	for _, topic := range log.Topics {
+		topics = append(topics, "0x"+hex.EncodeToString(topic[:]))
+	}
+	return fmt.Sprintf("log(topics: [%s], data: 0x%s) from %s", strings.Join(topics, ", "), hex.EncodeToString(log.Data), emitter)
+}
+
+// formatEvent formats a log decoded with the event (i.e. Transfer(from: 0x1, to: 0x2, value: 1))
+func formatEvent(event *abi.Event, log *state.Log) (string, bool) {
+	topics := []ethgo.Hash{}
+	for _, topic := range log.Topics {
+		topics = append(topics, ethgo.Hash(topic))
+	}
+	obj, err := abi.ParseLog(event.Inputs, &ethgo.Log{Topics: topics, Data: log.Data})
+	if err != nil {
+		return "", false
+	}
+	args := []string{}
+	for indx, elem := range event.Inputs.TupleElems() {
+		name := elem.Name
+		if name == "" {
+			name = strconv.Itoa(indx)
+		}
+		args = append(args, name+": "+formatValue(obj[elem.Name]))
+	}
+	return event.Name + "(" + strings.Join(args, ", ") + ")", true
+}
+
+// projectEvents returns the events of the contracts of the project by id
+func (v *vmCheatcode) projectEvents() map[ethgo.Hash]*abi.Event {
+	if v.events != nil {
+		return v.events
+	}
+	v.events = map[ethgo.Hash]*abi.Event{}
+	if v.project == nil {
+		return v.events
+	}
+	contracts, err := v.project.state.ListContracts()
+	if err != nil {
+		return v.events
+	}
+	for _, contract := range contracts {
+		contractABI, err := abi.NewABI(contract.Abi)
+		if err != nil {
+			continue
+		}
+		for _, event := range contractABI.Events {
+			// the anonymous events do not have an id
+			if !event.Anonymous {
+				v.events[event.ID()] = event
+			}
+		}
+	}
+	return v.events
+}
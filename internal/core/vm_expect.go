@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo/abi"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// expectedRevert is a revert expected for the next call of a contract
+type expectedRevert struct {
+	// caller is the contract that expects the revert
+	caller evmc.Address
+
+	// depth is the depth of the calls made by the caller
+	depth int
+
+	// data is the expected revert data, any revert matches if empty
+	data []byte
+
+	// selector signals whether only the selector of the data is matched
+	selector bool
+
+	// contract is the call that is expected to revert once it starts
+	contract *state.Contract
+}
+
+// match returns whether the revert data is the expected one
+func (e *expectedRevert) match(data []byte) bool {
+	if len(e.data) == 0 {
+		return true
+	}
+	if e.selector {
+		return len(data) >= 4 && bytes.Equal(data[:4], e.data)
+	}
+	if bytes.Equal(data, e.data) {
+		return true
+	}
+	// the expected data can be the reason of a revert(string)
+	reason, err := abi.UnpackRevertError(data)
+	return err == nil && reason == string(e.data)
+}
+
+// describe returns a description of the expected revert data
+func (e *expectedRevert) describe() string {
+	if e.selector {
+		return "selector 0x" + hex.EncodeToString(e.data)
+	}
+	if _, err := abi.UnpackRevertError(e.data); err == nil {
+		return describeRevert(e.data)
+	}
+	if isPrintable(e.data) {
+		return strconv.Quote(string(e.data))
+	}
+	return "0x" + hex.EncodeToString(e.data)
+}
+
+// describeRevert returns a description of the data of a revert
+func describeRevert(data []byte) string {
+	if len(data) == 0 {
+		return "no data"
+	}
+	if reason, err := abi.UnpackRevertError(data); err == nil {
+		return strconv.Quote(reason)
+	}
+	return decodeRevert(data)
+}
+
+func isPrintable(data []byte) bool {
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *vmCheatcode) expectRevert(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	if v.expectedRevert != nil {
+		return nil, fmt.Errorf("a revert is already expected")
+	}
+	e := &expectedRevert{
+		caller: ctx.Caller,
+		depth:  ctx.Depth,
+	}
+	if len(args) != 0 {
+		switch obj := args[0].(type) {
+		case [4]byte:
+			e.data = obj[:]
+			e.selector = true
+		case []byte:
+			e.data = obj
+		}
+	}
+	v.expectedRevert = e
+	return nil, nil
+}
+
+// beginExpectRevert marks the call as the one expected to revert
+func (v *vmCheatcode) beginExpectRevert(c *state.Contract) {
+	e := v.expectedRevert
+	if e == nil || e.contract != nil {
+		return
+	}
+	if c.Depth != e.depth || c.Caller != e.caller {
+		return
+	}
+	e.contract = c
+}
+
+// endExpectRevert turns the expected revert of the call into a success. If
+// the call does not revert as expected, it reverts with the explanation.
+func (v *vmCheatcode) endExpectRevert(c *state.Contract, output []byte, err error) ([]byte, error) {
+	e := v.expectedRevert
+	if e == nil || e.contract != c {
+		return output, err
+	}
+	v.expectedRevert = nil
+
+	var failure string
+	if err == nil {
+		failure = "expected a revert, but the call succeeded"
+	} else if !e.match(output) {
+		failure = fmt.Sprintf("expected a revert with %s, got %s", e.describe(), describeRevert(output))
+	}
+	if failure != "" {
+		failure = "expectRevert: " + failure
+		v.failures = append(v.failures, failure)
+		return encodeRevert(failure), state.ErrExecutionReverted
+	}
+	return nil, nil
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// permissions returns the accesses to the host allowed to the tests
+func (v *vmCheatcode) permissions() *Permissions {
+	if v.project == nil || v.project.config == nil {
+		return nil
+	}
+	return v.project.config.Permissions
+}
+
+// lookupEnv returns the value of an environment variable the tests can read
+func (v *vmCheatcode) lookupEnv(name string) (string, bool, error) {
+	if !v.permissions().CanReadEnv(name) {
+		return "", false, fmt.Errorf("environment variable '%s' is not in permissions.env", name)
+	}
+	value, ok := os.LookupEnv(name)
+	return value, ok, nil
+}
+
+// parseEnv parses the value of an environment variable with the type of typ
+func parseEnv(name, value string, typ interface{}) (interface{}, error) {
+	switch typ.(type) {
+	case *big.Int:
+		// decimal or 0x prefixed hex values
+		num, ok := new(big.Int).SetString(value, 0)
+		if !ok || num.Sign() < 0 || num.BitLen() > 256 {
+			return nil, fmt.Errorf("environment variable '%s' is not an uint256: '%s'", name, value)
+		}
+		return num, nil
+
+	case ethgo.Address:
+		buf, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil || len(buf) != 20 {
+			return nil, fmt.Errorf("environment variable '%s' is not an address: '%s'", name, value)
+		}
+		return ethgo.BytesToAddress(buf), nil
+
+	default:
+		return value, nil
+	}
+}
+
+func (v *vmCheatcode) env(typ interface{}) vmHandler {
+	return func(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+		name := args[0].(string)
+		value, ok, err := v.lookupEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("environment variable '%s' not found", name)
+		}
+		res, err := parseEnv(name, value, typ)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{res}, nil
+	}
+}
+
+func (v *vmCheatcode) envOr(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	name := args[0].(string)
+	value, ok, err := v.lookupEnv(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []interface{}{args[1]}, nil
+	}
+	res, err := parseEnv(name, value, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{res}, nil
+}
+
+// projectPath returns the clean path of a file of the project
+func projectPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("path '%s' is not relative to the project", path)
+	}
+	clean := filepath.Clean(path)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path '%s' is outside of the project", path)
+	}
+	return clean, nil
+}
+
+// evalSymlinks returns the path with the symlinks resolved. The path
+// may not exist yet, in which case its folder is resolved instead.
+func evalSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	// a dangling symlink cannot be resolved
+	if _, lerr := os.Lstat(path); !os.IsNotExist(err) || lerr == nil {
+		return "", err
+	}
+	dir, err := evalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// projectFile returns the path of a file of the project with the symlinks
+// resolved. Both the path and the resolved path must be allowed in the
+// list of the permissions.
+func (v *vmCheatcode) projectFile(path string, allowed func(string) bool, list string) (string, error) {
+	clean, err := projectPath(path)
+	if err != nil {
+		return "", err
+	}
+	if !allowed(clean) {
+		return "", fmt.Errorf("path '%s' is not in permissions.%s", path, list)
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	resolved, err := evalSymlinks(filepath.Join(root, clean))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path '%s' links outside of the project", path)
+	}
+	if !allowed(rel) {
+		return "", fmt.Errorf("path '%s' links to '%s', which is not in permissions.%s", path, rel, list)
+	}
+	return rel, nil
+}
+
+// readablePath returns the path of a file of the project the tests can read
+func (v *vmCheatcode) readablePath(path string) (string, error) {
+	return v.projectFile(path, v.permissions().CanRead, "read")
+}
+
+func (v *vmCheatcode) readFile(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	path, err := v.readablePath(args[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{string(data)}, nil
+}
+
+func (v *vmCheatcode) readLine(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	path, err := v.readablePath(args[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	if len(data) != 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	// each call returns the next line and an empty string at the end
+	if v.readLines == nil {
+		v.readLines = map[string]int{}
+	}
+	indx := v.readLines[path]
+	if indx >= len(lines) {
+		return []interface{}{""}, nil
+	}
+	v.readLines[path] = indx + 1
+	return []interface{}{strings.TrimSuffix(lines[indx], "\r")}, nil
+}
+
+func (v *vmCheatcode) writeFile(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	path, err := v.projectFile(args[0].(string), v.permissions().CanWrite, "write")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, []byte(args[1].(string)), 0644); err != nil {
+		return nil, err
+	}
+
+	// readLine starts again from the first line
+	delete(v.readLines, path)
+	return nil, nil
+}
+
+func (v *vmCheatcode) ffi(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	if !v.permissions().CanRunFFI() {
+		return nil, fmt.Errorf("local commands are not allowed, set permissions.ffi")
+	}
+	cmdArgs := args[0].([]string)
+	if len(cmdArgs) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, fmt.Errorf("command '%s' failed: %v", strings.Join(cmdArgs, " "), err)
+	}
+
+	// a 0x prefixed hex output is returned decoded (i.e. to abi.decode it)
+	output := strings.TrimSpace(stdout.String())
+	if strings.HasPrefix(output, "0x") {
+		if buf, err := hex.DecodeString(output[2:]); err == nil {
+			return []interface{}{buf}, nil
+		}
+	}
+	return []interface{}{[]byte(output)}, nil
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// mockedCall is the result of the calls with calldata that starts with data
+type mockedCall struct {
+	data   []byte
+	output []byte
+	revert bool
+}
+
+func (v *vmCheatcode) mockCall(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	v.addMockedCall(ctx, args, false)
+	return nil, nil
+}
+
+func (v *vmCheatcode) mockCallRevert(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	v.addMockedCall(ctx, args, true)
+	return nil, nil
+}
+
+func (v *vmCheatcode) clearMockedCalls(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	v.mockedCalls = nil
+	return nil, nil
+}
+
+func (v *vmCheatcode) addMockedCall(ctx *state.CallContext, args []interface{}, revert bool) {
+	callee := evmc.Address(args[0].(ethgo.Address))
+	mock := &mockedCall{
+		data:   args[1].([]byte),
+		output: args[2].([]byte),
+		revert: revert,
+	}
+
+	// solidity checks that the callee has code before the call
+	txn := ctx.Transition.Txn()
+	if txn.GetCodeSize(callee) == 0 {
+		txn.SetCode(callee, []byte{0x0})
+	}
+
+	if v.mockedCalls == nil {
+		v.mockedCalls = map[evmc.Address][]*mockedCall{}
+	}
+	mocks := []*mockedCall{}
+	for _, m := range v.mockedCalls[callee] {
+		// a new mock with the same data replaces the previous one
+		if !bytes.Equal(m.data, mock.data) {
+			mocks = append(mocks, m)
+		}
+	}
+	v.mockedCalls[callee] = append(mocks, mock)
+}
+
+// MockCall returns the result of the mocked call with the longest data
+// that is a prefix of the calldata
+func (v *vmCheatcode) MockCall(c *state.Contract) ([]byte, bool, bool) {
+	var found *mockedCall
+	for _, m := range v.mockedCalls[c.CodeAddress] {
+		if !bytes.HasPrefix(c.Input, m.data) {
+			continue
+		}
+		if found == nil || len(m.data) > len(found.data) {
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, false, false
+	}
+	return found.output, found.revert, true
+}
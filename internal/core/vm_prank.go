@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// prank overrides the caller of the calls made by a contract
+type prank struct {
+	// caller is the contract that started the prank
+	caller evmc.Address
+
+	// depth is the depth of the calls made by the caller
+	depth int
+
+	// sender is the caller of the pranked calls
+	sender evmc.Address
+
+	// origin is the tx.origin of the pranked calls if set
+	origin *evmc.Address
+
+	// single signals whether the prank only applies to the next call
+	single bool
+}
+
+// prankedCall is a running call with a prank
+type prankedCall struct {
+	contract *state.Contract
+
+	// origin is the tx.origin before the call
+	origin evmc.Address
+}
+
+func newPrank(ctx *state.CallContext, args []interface{}, single bool) *prank {
+	p := &prank{
+		caller: ctx.Caller,
+		depth:  ctx.Depth,
+		sender: evmc.Address(args[0].(ethgo.Address)),
+		single: single,
+	}
+	if len(args) > 1 {
+		origin := evmc.Address(args[1].(ethgo.Address))
+		p.origin = &origin
+	}
+	return p
+}
+
+func (v *vmCheatcode) prankCall(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	if v.prank != nil {
+		return nil, fmt.Errorf("a prank is already active, stop it with stopPrank")
+	}
+	v.prank = newPrank(ctx, args, true)
+	return nil, nil
+}
+
+func (v *vmCheatcode) startPrank(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	if v.prank != nil {
+		return nil, fmt.Errorf("a prank is already active, stop it with stopPrank")
+	}
+	v.prank = newPrank(ctx, args, false)
+	return nil, nil
+}
+
+func (v *vmCheatcode) stopPrank(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	v.prank = nil
+	return nil, nil
+}
+
+// beginPrank overrides the caller of the call if there is an active prank
+func (v *vmCheatcode) beginPrank(t *state.Transition, c *state.Contract) {
+	p := v.prank
+	if p == nil {
+		return
+	}
+	if c.Depth != p.depth || c.Caller != p.caller {
+		return
+	}
+	// delegate calls keep the caller of the contract
+	if c.Type == evmc.DelegateCall || c.Type == evmc.CallCode {
+		return
+	}
+
+	ctx := t.Context()
+	v.pranked = append(v.pranked, &prankedCall{
+		contract: c,
+		origin:   ctx.Origin,
+	})
+
+	c.Caller = p.sender
+	if p.origin != nil {
+		ctx.Origin = *p.origin
+	}
+	if p.single {
+		v.prank = nil
+	}
+}
+
+// endPrank restores the origin after a pranked call
+func (v *vmCheatcode) endPrank(t *state.Transition, c *state.Contract) {
+	if len(v.pranked) == 0 {
+		return
+	}
+	last := v.pranked[len(v.pranked)-1]
+	if last.contract != c {
+		return
+	}
+	t.Context().Origin = last.origin
+	v.pranked = v.pranked[:len(v.pranked)-1]
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+)
+
+// storageAccesses are the storage slots read and written by an account
+type storageAccesses struct {
+	reads  [][32]byte
+	writes [][32]byte
+}
+
+func (v *vmCheatcode) record(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	// start again if the accesses are already being recorded
+	v.accesses = map[evmc.Address]*storageAccesses{}
+	return nil, nil
+}
+
+func (v *vmCheatcode) getAccesses(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	res := &storageAccesses{
+		reads:  [][32]byte{},
+		writes: [][32]byte{},
+	}
+	if accesses, ok := v.accesses[evmc.Address(args[0].(ethgo.Address))]; ok {
+		res = accesses
+	}
+	return []interface{}{res.reads, res.writes}, nil
+}
+
+func (v *vmCheatcode) recordLogs(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	v.recordedLogs = []*state.Log{}
+	return nil, nil
+}
+
+func (v *vmCheatcode) getRecordedLogs(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	logs := []map[string]interface{}{}
+	for _, log := range v.recordedLogs {
+		topics := [][32]byte{}
+		for _, topic := range log.Topics {
+			topics = append(topics, topic)
+		}
+		logs = append(logs, map[string]interface{}{
+			"topics":  topics,
+			"data":    log.Data,
+			"emitter": ethgo.Address(log.Address),
+		})
+	}
+
+	// the logs are only returned once
+	if v.recordedLogs != nil {
+		v.recordedLogs = []*state.Log{}
+	}
+	return []interface{}{logs}, nil
+}
+
+// storageAccess returns the accesses of the account if they are being recorded
+func (v *vmCheatcode) storageAccess(addr evmc.Address) *storageAccesses {
+	if v.accesses == nil {
+		return nil
+	}
+	accesses, ok := v.accesses[addr]
+	if !ok {
+		accesses = &storageAccesses{
+			reads:  [][32]byte{},
+			writes: [][32]byte{},
+		}
+		v.accesses[addr] = accesses
+	}
+	return accesses
+}
+
+func (v *vmCheatcode) OnStorageRead(addr evmc.Address, key evmc.Hash) {
+	if accesses := v.storageAccess(addr); accesses != nil {
+		accesses.reads = append(accesses.reads, key)
+	}
+}
+
+func (v *vmCheatcode) OnStorageWrite(addr evmc.Address, key evmc.Hash) {
+	if accesses := v.storageAccess(addr); accesses != nil {
+		accesses.writes = append(accesses.writes, key)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/umbracle/ethgo"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+	state2 "github.com/umbracle/greenhouse/internal/state"
+)
+
+func (v *vmCheatcode) deal(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Txn().SetBalance(evmc.Address(args[0].(ethgo.Address)), args[1].(*big.Int))
+	return nil, nil
+}
+
+func (v *vmCheatcode) store(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Txn().SetState(evmc.Address(args[0].(ethgo.Address)), args[1].([32]byte), args[2].([32]byte))
+	return nil, nil
+}
+
+func (v *vmCheatcode) load(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	value := ctx.Transition.Txn().GetState(evmc.Address(args[0].(ethgo.Address)), args[1].([32]byte))
+	return []interface{}{[32]byte(value)}, nil
+}
+
+func (v *vmCheatcode) etch(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Txn().SetCode(evmc.Address(args[0].(ethgo.Address)), args[1].([]byte))
+	return nil, nil
+}
+
+func (v *vmCheatcode) setNonce(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	ctx.Transition.Txn().SetNonce(evmc.Address(args[0].(ethgo.Address)), args[1].(uint64))
+	return nil, nil
+}
+
+func (v *vmCheatcode) getNonce(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	nonce := ctx.Transition.Txn().GetNonce(evmc.Address(args[0].(ethgo.Address)))
+	return []interface{}{nonce}, nil
+}
+
+func (v *vmCheatcode) getCode(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	contract, err := v.artifact(args[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	code, err := decodeCode(contract, contract.Bin)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{code}, nil
+}
+
+func (v *vmCheatcode) getDeployedCode(ctx *state.CallContext, args []interface{}) ([]interface{}, error) {
+	contract, err := v.artifact(args[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	code, err := decodeCode(contract, contract.BinRuntime)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{code}, nil
+}
+
+// artifact returns the contract of the project with the name. The name is
+// either the name of the contract (i.e. Token) or the file and the name
+// of the contract (i.e. Token.sol:Token) if there are many with the same name.
+func (v *vmCheatcode) artifact(name string) (*state2.Contract, error) {
+	if v.project == nil {
+		return nil, fmt.Errorf("contract '%s' not found", name)
+	}
+	indx := strings.LastIndex(name, ":")
+	if indx == -1 {
+		return v.project.Contract(name)
+	}
+
+	path, name := name[:indx], name[indx+1:]
+	contracts, err := v.project.Contracts()
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range contracts {
+		if contract.Name != name {
+			continue
+		}
+		if contract.Filename == path || filepath.Join(contract.Dir, contract.Filename) == filepath.Clean(path) {
+			return contract, nil
+		}
+	}
+	return nil, fmt.Errorf("contract '%s' not found in '%s'", name, path)
+}
+
+// decodeCode decodes the hex bytecode of the contract
+func decodeCode(contract *state2.Contract, bin string) ([]byte, error) {
+	if strings.Contains(bin, "__") {
+		return nil, fmt.Errorf("contract '%s' has unlinked libraries", contract.Name)
+	}
+	code, err := hex.DecodeString(bin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the code of '%s': %v", contract.Name, err)
+	}
+	return code, nil
+}
@@ -0,0 +1,1205 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	state "github.com/umbracle/greenhouse/internal/runtime"
+	"github.com/umbracle/greenhouse/internal/standard"
+	state2 "github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestVMCheatcode_Handlers(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	for _, method := range standard.VMMethods {
+		_, ok := vm.handlers[method.Sig()]
+		assert.True(t, ok, method.Sig())
+	}
+	assert.Len(t, vm.handlers, len(standard.VMMethods))
+}
+
+// callVM applies a call to the vm cheatcode with the given method and arguments
+func callVM(t *testing.T, transition *state.Transition, signature string, args ...interface{}) *state.Output {
+	method, err := abi.NewMethod(signature)
+	assert.NoError(t, err)
+
+	input, err := method.Encode(args)
+	assert.NoError(t, err)
+
+	to := evmc.Address(standard.VMAddress)
+	return transition.Apply(&state.Message{
+		From:     evmc.Address{0x1},
+		To:       &to,
+		Gas:      1000000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+		Input:    input,
+	})
+}
+
+func TestVMCheatcode_Environment(t *testing.T) {
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(nil)))
+	ctx := transition.Context()
+
+	assert.True(t, callVM(t, transition, "warp(uint256)", big.NewInt(100)).Success)
+	assert.Equal(t, int64(100), ctx.Timestamp)
+
+	assert.True(t, callVM(t, transition, "roll(uint256)", big.NewInt(10)).Success)
+	assert.Equal(t, int64(10), ctx.Number)
+
+	assert.True(t, callVM(t, transition, "fee(uint256)", big.NewInt(7)).Success)
+	assert.Equal(t, byte(7), ctx.BaseFee[31])
+
+	assert.True(t, callVM(t, transition, "chainId(uint256)", big.NewInt(1337)).Success)
+	assert.Equal(t, int64(1337), ctx.ChainID)
+
+	coinbase := ethgo.Address{0x5}
+	assert.True(t, callVM(t, transition, "coinbase(address)", coinbase).Success)
+	assert.Equal(t, evmc.Address(coinbase), ctx.Coinbase)
+
+	assert.True(t, callVM(t, transition, "difficulty(uint256)", big.NewInt(3)).Success)
+	assert.Equal(t, byte(3), ctx.Difficulty[31])
+
+	assert.True(t, callVM(t, transition, "prevrandao(bytes32)", [32]byte{0x9}).Success)
+	assert.Equal(t, evmc.Hash{0x9}, ctx.Difficulty)
+
+	assert.True(t, callVM(t, transition, "txGasPrice(uint256)", big.NewInt(2)).Success)
+	assert.Equal(t, byte(2), ctx.GasPrice[31])
+}
+
+func TestVMCheatcode_Revert(t *testing.T) {
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(nil)))
+
+	// the timestamp does not fit in the context
+	max := new(big.Int).Lsh(big.NewInt(1), 64)
+	output := callVM(t, transition, "warp(uint256)", max)
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: warp: value 18446744073709551616 out of range", decodeRevert(output.ReturnValue))
+
+	output = callVM(t, transition, "unknown()")
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: unknown cheatcode 0x3d0a387c", decodeRevert(output.ReturnValue))
+}
+
+// callCode returns the bytecode that calls the contract with the input
+// and writes 64 bytes of the output in memory at offset
+func callCode(to evmc.Address, input []byte, offset byte) string {
+	code := ""
+	for i := 0; i < len(input); i += 32 {
+		chunk := make([]byte, 32)
+		copy(chunk, input[i:])
+		code += "7f" + hex.EncodeToString(chunk) + "61" + fmt.Sprintf("%04x", 0x100+i) + "52"
+	}
+	code += "6040" + "60" + fmt.Sprintf("%02x", offset) + "60" + fmt.Sprintf("%02x", len(input)) + "610100" + "6000"
+	code += "73" + hex.EncodeToString(to[:]) + "5af150"
+	return code
+}
+
+func encodeVMCall(t *testing.T, signature string, args ...interface{}) []byte {
+	method, err := abi.NewMethod(signature)
+	assert.NoError(t, err)
+
+	input, err := method.Encode(args)
+	assert.NoError(t, err)
+	return input
+}
+
+func TestVMCheatcode_Prank(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	from := evmc.Address{0x1}
+	testAddr := evmc.Address{0x2}
+	targetAddr := evmc.Address{0x3}
+
+	sender := ethgo.Address{0x4}
+	origin := ethgo.Address{0x5}
+
+	// the target returns msg.sender and tx.origin
+	targetCode := "33600052326020526040" + "6000f3"
+
+	cases := []struct {
+		calls    []string
+		expected []evmc.Address
+	}{
+		{
+			[]string{
+				callCode(vmAddr, encodeVMCall(t, "prank(address)", sender), 0xe0),
+				callCode(targetAddr, nil, 0x0),
+				callCode(targetAddr, nil, 0x40),
+			},
+			[]evmc.Address{
+				evmc.Address(sender), from,
+				testAddr, from,
+			},
+		},
+		{
+			[]string{
+				callCode(vmAddr, encodeVMCall(t, "startPrank(address,address)", sender, origin), 0xe0),
+				callCode(targetAddr, nil, 0x0),
+				callCode(targetAddr, nil, 0x40),
+				callCode(vmAddr, encodeVMCall(t, "stopPrank()"), 0xe0),
+				callCode(targetAddr, nil, 0x80),
+			},
+			[]evmc.Address{
+				evmc.Address(sender), evmc.Address(origin),
+				evmc.Address(sender), evmc.Address(origin),
+				testAddr, from,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		vm := newVMCheatcode(nil)
+		transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+		code := strings.Join(c.calls, "") + "60" + fmt.Sprintf("%02x", len(c.expected)*32) + "6000f3"
+		testCode, err := hex.DecodeString(code)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(testAddr, testCode)
+
+		buf, err := hex.DecodeString(targetCode)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(targetAddr, buf)
+
+		output := transition.Apply(&state.Message{
+			From:     from,
+			To:       &testAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.True(t, output.Success)
+
+		for indx, addr := range c.expected {
+			assert.Equal(t, addr[:], output.ReturnValue[indx*32+12:(indx+1)*32], indx)
+		}
+		// the origin is restored after the pranked calls
+		assert.Equal(t, from, transition.Context().Origin)
+	}
+}
+
+func TestVMCheatcode_PrankActive(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+	assert.True(t, callVM(t, transition, "startPrank(address)", ethgo.Address{0x1}).Success)
+
+	output := callVM(t, transition, "prank(address)", ethgo.Address{0x1})
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: prank: a prank is already active, stop it with stopPrank", decodeRevert(output.ReturnValue))
+}
+
+func TestVMCheatcode_State(t *testing.T) {
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(nil)))
+	txn := transition.Txn()
+
+	addr := ethgo.Address{0x5}
+	slot, value := [32]byte{0x1}, [32]byte{0x2}
+
+	assert.True(t, callVM(t, transition, "deal(address,uint256)", addr, big.NewInt(100)).Success)
+	assert.Equal(t, big.NewInt(100), txn.GetBalance(evmc.Address(addr)))
+
+	assert.True(t, callVM(t, transition, "store(address,bytes32,bytes32)", addr, slot, value).Success)
+	assert.Equal(t, evmc.Hash(value), txn.GetState(evmc.Address(addr), slot))
+
+	output := callVM(t, transition, "load(address,bytes32)", addr, slot)
+	assert.True(t, output.Success)
+	assert.Equal(t, value[:], output.ReturnValue)
+
+	assert.True(t, callVM(t, transition, "etch(address,bytes)", addr, []byte{0x1, 0x2}).Success)
+	assert.Equal(t, []byte{0x1, 0x2}, txn.GetCode(evmc.Address(addr)))
+
+	assert.True(t, callVM(t, transition, "setNonce(address,uint64)", addr, uint64(10)).Success)
+	assert.Equal(t, uint64(10), txn.GetNonce(evmc.Address(addr)))
+
+	output = callVM(t, transition, "getNonce(address)", addr)
+	assert.True(t, output.Success)
+	assert.Equal(t, byte(10), output.ReturnValue[31])
+}
+
+func TestVMCheatcode_GetCode(t *testing.T) {
+	s, err := state2.NewState()
+	assert.NoError(t, err)
+
+	contracts := []*state2.Contract{
+		{Dir: "contracts", Filename: "A.sol", Name: "A", Bin: "0102", BinRuntime: "02"},
+		{Dir: "contracts", Filename: "B.sol", Name: "B", Bin: "0304", BinRuntime: "04"},
+		{Dir: "contracts", Filename: "D.sol", Name: "D", Bin: "__$abc$__"},
+	}
+	for _, contract := range contracts {
+		assert.NoError(t, s.UpsertContract(contract))
+	}
+	p := &Project{state: s, libDirectory: "lib"}
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(p)))
+
+	getCode := func(signature, name string) []byte {
+		output := callVM(t, transition, signature, name)
+		if !output.Success {
+			return []byte(decodeRevert(output.ReturnValue))
+		}
+		method, err := abi.NewMethod(signature + " returns (bytes)")
+		assert.NoError(t, err)
+
+		res, err := method.Decode(output.ReturnValue)
+		assert.NoError(t, err)
+		return res["0"].([]byte)
+	}
+
+	assert.Equal(t, []byte{0x1, 0x2}, getCode("getCode(string)", "A"))
+	assert.Equal(t, []byte{0x2}, getCode("getDeployedCode(string)", "A"))
+	assert.Equal(t, []byte{0x3, 0x4}, getCode("getCode(string)", "B.sol:B"))
+	assert.Equal(t, []byte{0x3, 0x4}, getCode("getCode(string)", "contracts/B.sol:B"))
+
+	assert.Equal(t, "vm: getCode: contract 'B' not found in 'C.sol'", string(getCode("getCode(string)", "C.sol:B")))
+	assert.Equal(t, "vm: getCode: contract 'D' has unlinked libraries", string(getCode("getCode(string)", "D")))
+	assert.Equal(t, "vm: getCode: contract 'E' not found", string(getCode("getCode(string)", "E")))
+}
+
+// revertCode returns the bytecode that reverts with the data
+func revertCode(data []byte) string {
+	code := ""
+	for i := 0; i < len(data); i += 32 {
+		chunk := make([]byte, 32)
+		copy(chunk, data[i:])
+		code += "7f" + hex.EncodeToString(chunk) + "60" + fmt.Sprintf("%02x", i) + "52"
+	}
+	return code + "60" + fmt.Sprintf("%02x", len(data)) + "6000fd"
+}
+
+func TestVMCheatcode_ExpectRevert(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	testAddr := evmc.Address{0x2}
+	targetAddr := evmc.Address{0x3}
+
+	customErr := append(ethgo.Keccak256([]byte("Custom(uint256)"))[:4], make([]byte, 32)...)
+	otherErr := ethgo.Keccak256([]byte("Other()"))[:4]
+
+	var selector [4]byte
+	copy(selector[:], customErr)
+
+	cases := []struct {
+		expect  []byte
+		target  string
+		failure string
+	}{
+		{
+			encodeVMCall(t, "expectRevert()"),
+			revertCode(nil),
+			"",
+		},
+		{
+			// the changes of the call are reverted
+			encodeVMCall(t, "expectRevert()"),
+			"600160005500",
+			"expectRevert: expected a revert, but the call succeeded",
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes4)", selector),
+			revertCode(customErr),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes4)", selector),
+			revertCode(otherErr),
+			"expectRevert: expected a revert with selector 0x" + hex.EncodeToString(selector[:]) + ", got 0x" + hex.EncodeToString(otherErr),
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes)", customErr),
+			revertCode(customErr),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes)", []byte("reason")),
+			revertCode(encodeRevert("reason")),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes)", encodeRevert("reason")),
+			revertCode(encodeRevert("reason")),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes)", []byte("reason")),
+			revertCode(encodeRevert("other")),
+			`expectRevert: expected a revert with "reason", got "other"`,
+		},
+		{
+			encodeVMCall(t, "expectRevert(bytes)", []byte("reason")),
+			revertCode(nil),
+			`expectRevert: expected a revert with "reason", got no data`,
+		},
+	}
+
+	for _, c := range cases {
+		vm := newVMCheatcode(nil)
+		vm.reset()
+		transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+		// return the return data of the call to the target
+		code := callCode(vmAddr, c.expect, 0xe0) + callCode(targetAddr, nil, 0x0) + "3d600060003e3d6000f3"
+		testCode, err := hex.DecodeString(code)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(testAddr, testCode)
+
+		targetCode, err := hex.DecodeString(c.target)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(targetAddr, targetCode)
+
+		output := transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &testAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.True(t, output.Success)
+
+		if c.failure == "" {
+			assert.Empty(t, vm.finish())
+			assert.Empty(t, output.ReturnValue)
+		} else {
+			assert.Equal(t, []string{c.failure}, vm.finish())
+			assert.Equal(t, c.failure, decodeRevert(output.ReturnValue))
+		}
+		assert.Equal(t, evmc.Hash{}, transition.Txn().GetState(targetAddr, evmc.Hash{}))
+	}
+}
+
+func TestVMCheatcode_ExpectRevertNoCall(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+	assert.True(t, callVM(t, transition, "expectRevert()").Success)
+	assert.Equal(t, []string{"expectRevert: expected a revert, but no call was made"}, vm.finish())
+}
+
+// logCode returns the bytecode that emits a log with the topics and the data
+func logCode(topics []evmc.Hash, data []byte) string {
+	code := ""
+	for i := 0; i < len(data); i += 32 {
+		chunk := make([]byte, 32)
+		copy(chunk, data[i:])
+		code += "7f" + hex.EncodeToString(chunk) + "60" + fmt.Sprintf("%02x", i) + "52"
+	}
+	for i := len(topics) - 1; i >= 0; i-- {
+		code += "7f" + hex.EncodeToString(topics[i][:])
+	}
+	return code + "60" + fmt.Sprintf("%02x", len(data)) + "6000" + fmt.Sprintf("%02x", 0xa0+len(topics))
+}
+
+func TestVMCheatcode_ExpectEmit(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	testAddr := evmc.Address{0x2}
+	targetAddr := evmc.Address{0x3}
+
+	event := abi.MustNewEvent("event Transfer(address indexed from, address indexed to, uint256 value)")
+	id := evmc.Hash(event.ID())
+	from, to, other := evmc.Hash{31: 0x1}, evmc.Hash{31: 0x2}, evmc.Hash{31: 0x3}
+
+	template := logCode([]evmc.Hash{id, from, to}, []byte{31: 0x1})
+	transfer := "Transfer(from: 0x0000000000000000000000000000000000000001, to: 0x0000000000000000000000000000000000000002, value: 1)"
+
+	cases := []struct {
+		expect  []byte
+		target  string
+		failure string
+	}{
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, true),
+			template,
+			"",
+		},
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, true),
+			logCode([]evmc.Hash{id, from, other}, []byte{31: 0x1}),
+			"expectEmit: expected " + transfer + " from " + ethgo.Address(testAddr).String() +
+				", got Transfer(from: 0x0000000000000000000000000000000000000001, to: 0x0000000000000000000000000000000000000003, value: 1) from " + ethgo.Address(targetAddr).String(),
+		},
+		{
+			// the second topic is not checked
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, false, true, true),
+			logCode([]evmc.Hash{id, from, other}, []byte{31: 0x1}),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, true),
+			logCode([]evmc.Hash{id, from, to}, []byte{31: 0x2}),
+			"expectEmit: expected " + transfer + " from " + ethgo.Address(testAddr).String() +
+				", got Transfer(from: 0x0000000000000000000000000000000000000001, to: 0x0000000000000000000000000000000000000002, value: 2) from " + ethgo.Address(targetAddr).String(),
+		},
+		{
+			// the data is not checked
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, false),
+			logCode([]evmc.Hash{id, from, to}, []byte{31: 0x2}),
+			"",
+		},
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool,address)", true, true, true, true, ethgo.Address(targetAddr)),
+			template,
+			"",
+		},
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool,address)", true, true, true, true, ethgo.Address{0x9}),
+			template,
+			"expectEmit: expected " + transfer + " from " + ethgo.Address(testAddr).String() + ", got " + transfer + " from " + ethgo.Address(targetAddr).String(),
+		},
+		{
+			encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, true),
+			"",
+			"expectEmit: expected " + transfer + " from " + ethgo.Address(testAddr).String() + ", got no events",
+		},
+	}
+
+	for _, c := range cases {
+		vm := newVMCheatcode(nil)
+		vm.reset()
+		vm.events = map[ethgo.Hash]*abi.Event{event.ID(): event}
+		transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+		// return the return data of the call to the target
+		code := callCode(vmAddr, c.expect, 0xe0) + template + callCode(targetAddr, nil, 0x0) + "3d600060003e3d6000f3"
+		testCode, err := hex.DecodeString(code)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(testAddr, testCode)
+
+		targetCode, err := hex.DecodeString(c.target + "00")
+		assert.NoError(t, err)
+		transition.Txn().SetCode(targetAddr, targetCode)
+
+		output := transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &testAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.True(t, output.Success)
+
+		if c.failure == "" {
+			assert.Empty(t, vm.finish())
+
+			// the expected log of the test is not part of the output
+			assert.Len(t, output.Logs, 1)
+			assert.Equal(t, targetAddr, output.Logs[0].Address)
+		} else {
+			assert.Equal(t, []string{c.failure}, vm.finish())
+			assert.Equal(t, c.failure, decodeRevert(output.ReturnValue))
+		}
+	}
+}
+
+func TestVMCheatcode_ExpectEmitRevertedCall(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	testAddr := evmc.Address{0x2}
+	targetAddr := evmc.Address{0x3}
+	innerAddr := evmc.Address{0x4}
+
+	event := abi.MustNewEvent("event Transfer(address indexed from, address indexed to, uint256 value)")
+	template := logCode([]evmc.Hash{evmc.Hash(event.ID()), {31: 0x1}, {31: 0x2}}, []byte{31: 0x1})
+	transfer := "Transfer(from: 0x0000000000000000000000000000000000000001, to: 0x0000000000000000000000000000000000000002, value: 1)"
+
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	vm.events = map[ethgo.Hash]*abi.Event{event.ID(): event}
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+	expect := encodeVMCall(t, "expectEmit(bool,bool,bool,bool)", true, true, true, true)
+	code := callCode(vmAddr, expect, 0xe0) + template + callCode(targetAddr, nil, 0x0) + "3d600060003e3d6000f3"
+	testCode, err := hex.DecodeString(code)
+	assert.NoError(t, err)
+	transition.Txn().SetCode(testAddr, testCode)
+
+	// the target catches the revert of the call that emits the log
+	targetCode, err := hex.DecodeString(callCode(innerAddr, nil, 0x0) + "00")
+	assert.NoError(t, err)
+	transition.Txn().SetCode(targetAddr, targetCode)
+
+	innerCode, err := hex.DecodeString(template + revertCode(nil))
+	assert.NoError(t, err)
+	transition.Txn().SetCode(innerAddr, innerCode)
+
+	output := transition.Apply(&state.Message{
+		From:     evmc.Address{0x1},
+		To:       &testAddr,
+		Gas:      1000000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	})
+	assert.True(t, output.Success)
+
+	failure := "expectEmit: expected " + transfer + " from " + ethgo.Address(testAddr).String() + ", got no events"
+	assert.Equal(t, []string{failure}, vm.finish())
+	assert.Equal(t, failure, decodeRevert(output.ReturnValue))
+}
+
+func TestVMCheatcode_ExpectEmitNoLog(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+	assert.True(t, callVM(t, transition, "expectEmit(bool,bool,bool,bool)", true, true, true, true).Success)
+	assert.Equal(t, []string{"expectEmit: expected an event emitted by the test, but none was emitted"}, vm.finish())
+}
+
+func TestVMCheatcode_ExpectCall(t *testing.T) {
+	sender := evmc.Address{0x1}
+	target := ethgo.Address{0x3}
+
+	data := []byte{0x1, 0x2}
+	input := []byte{0x1, 0x2, 0x3}
+
+	cases := []struct {
+		expect  []byte
+		calls   int
+		value   int64
+		failure string
+	}{
+		{
+			encodeVMCall(t, "expectCall(address,bytes)", target, data),
+			1, 0,
+			"",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,bytes)", target, data),
+			0, 0,
+			"expectCall: expected a call to " + target.String() + " with data 0x0102, got none",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,bytes)", target, []byte{0x2}),
+			1, 0,
+			"expectCall: expected a call to " + target.String() + " with data 0x02, got none",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,uint256,bytes)", target, big.NewInt(5), data),
+			1, 5,
+			"",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,uint256,bytes)", target, big.NewInt(5), data),
+			1, 0,
+			"expectCall: expected a call to " + target.String() + " with data 0x0102 and value 5, got none",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,bytes,uint64)", target, data, uint64(2)),
+			2, 0,
+			"",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,bytes,uint64)", target, data, uint64(2)),
+			3, 0,
+			"expectCall: expected 2 calls to " + target.String() + " with data 0x0102, got 3",
+		},
+		{
+			encodeVMCall(t, "expectCall(address,uint256,bytes,uint64)", target, big.NewInt(5), data, uint64(0)),
+			1, 5,
+			"expectCall: expected 0 calls to " + target.String() + " with data 0x0102 and value 5, got 1",
+		},
+	}
+
+	vmAddr := evmc.Address(standard.VMAddress)
+	testAddr := evmc.Address{0x2}
+	routerAddr := evmc.Address{0x4}
+
+	for _, c := range cases {
+		vm := newVMCheatcode(nil)
+		vm.reset()
+		transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+		transition.Txn().SetBalance(routerAddr, big.NewInt(100))
+
+		// the router makes the calls to the target
+		routerCode := "7f" + hex.EncodeToString(append(append([]byte{}, input...), make([]byte, 32-len(input))...)) + "61010052"
+		for i := 0; i < c.calls; i++ {
+			routerCode += "60006000" + fmt.Sprintf("60%02x", len(input)) + "610100" + fmt.Sprintf("60%02x", c.value) + "73" + hex.EncodeToString(target[:]) + "5af150"
+		}
+		code, err := hex.DecodeString(routerCode + "00")
+		assert.NoError(t, err)
+		transition.Txn().SetCode(routerAddr, code)
+
+		// only the calls of the next call of the test are recorded, not
+		// the call to the target made after it
+		testCode := callCode(vmAddr, c.expect, 0xe0) + callCode(routerAddr, nil, 0x0) + callCode(evmc.Address(target), input, 0x0) + "00"
+		code, err = hex.DecodeString(testCode)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(testAddr, code)
+
+		assert.True(t, transition.Apply(&state.Message{
+			From:     sender,
+			To:       &testAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(0),
+			Value:    big.NewInt(0),
+		}).Success)
+
+		if c.failure == "" {
+			assert.Empty(t, vm.finish())
+		} else {
+			assert.Equal(t, []string{c.failure}, vm.finish())
+		}
+	}
+}
+
+func TestVMCheatcode_MockCall(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithMock(vm))
+
+	callee := ethgo.Address{0x3}
+	call := func(input []byte) *state.Output {
+		to := evmc.Address(callee)
+		return transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &to,
+			Gas:      1000000,
+			GasPrice: big.NewInt(0),
+			Value:    big.NewInt(0),
+			Input:    input,
+		})
+	}
+
+	assert.True(t, callVM(t, transition, "mockCall(address,bytes,bytes)", callee, []byte{0x1}, []byte{0xa}).Success)
+	assert.True(t, callVM(t, transition, "mockCall(address,bytes,bytes)", callee, []byte{0x1, 0x2}, []byte{0xb}).Success)
+	assert.True(t, callVM(t, transition, "mockCallRevert(address,bytes,bytes)", callee, []byte{0x2}, []byte{0xc}).Success)
+
+	// the callee has code for the checks of solidity
+	assert.NotEmpty(t, transition.Txn().GetCode(evmc.Address(callee)))
+
+	output := call([]byte{0x1, 0x3})
+	assert.True(t, output.Success)
+	assert.Equal(t, []byte{0xa}, output.ReturnValue)
+
+	// the longest prefix is used
+	output = call([]byte{0x1, 0x2, 0x3})
+	assert.True(t, output.Success)
+	assert.Equal(t, []byte{0xb}, output.ReturnValue)
+
+	output = call([]byte{0x2})
+	assert.False(t, output.Success)
+	assert.Equal(t, []byte{0xc}, output.ReturnValue)
+
+	// the same data replaces the mock
+	assert.True(t, callVM(t, transition, "mockCall(address,bytes,bytes)", callee, []byte{0x2}, []byte{0xd}).Success)
+	output = call([]byte{0x2})
+	assert.True(t, output.Success)
+	assert.Equal(t, []byte{0xd}, output.ReturnValue)
+
+	// the calls without a mock run the code
+	output = call([]byte{0x3})
+	assert.True(t, output.Success)
+	assert.Empty(t, output.ReturnValue)
+
+	assert.True(t, callVM(t, transition, "clearMockedCalls()").Success)
+	output = call([]byte{0x1})
+	assert.True(t, output.Success)
+	assert.Empty(t, output.ReturnValue)
+}
+
+func TestVMCheatcode_Snapshot(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm))
+	txn := transition.Txn()
+
+	addr := ethgo.Address{0x5}
+	revertTo := func(id *big.Int) bool {
+		output := callVM(t, transition, "revertTo(uint256)", id)
+		assert.True(t, output.Success)
+		return output.ReturnValue[31] == 1
+	}
+
+	assert.True(t, callVM(t, transition, "deal(address,uint256)", addr, big.NewInt(1)).Success)
+	assert.True(t, callVM(t, transition, "warp(uint256)", big.NewInt(10)).Success)
+
+	output := callVM(t, transition, "snapshot()")
+	assert.True(t, output.Success)
+	id := new(big.Int).SetBytes(output.ReturnValue)
+
+	assert.True(t, callVM(t, transition, "deal(address,uint256)", addr, big.NewInt(2)).Success)
+	assert.True(t, callVM(t, transition, "warp(uint256)", big.NewInt(20)).Success)
+
+	assert.True(t, revertTo(id))
+	assert.Equal(t, big.NewInt(1), txn.GetBalance(evmc.Address(addr)))
+	assert.Equal(t, int64(10), transition.Context().Timestamp)
+
+	// the snapshot can be used again
+	assert.True(t, callVM(t, transition, "deal(address,uint256)", addr, big.NewInt(3)).Success)
+	assert.True(t, revertTo(id))
+	assert.Equal(t, big.NewInt(1), txn.GetBalance(evmc.Address(addr)))
+
+	// the snapshots taken after the snapshot are discarded
+	output = callVM(t, transition, "snapshot()")
+	assert.True(t, output.Success)
+	later := new(big.Int).SetBytes(output.ReturnValue)
+
+	assert.True(t, revertTo(id))
+	assert.False(t, revertTo(later))
+
+	assert.False(t, revertTo(big.NewInt(1000)))
+	assert.False(t, revertTo(new(big.Int).Lsh(big.NewInt(1), 100)))
+}
+
+func TestVMCheatcode_SnapshotNestedCall(t *testing.T) {
+	vmAddr := evmc.Address(standard.VMAddress)
+	testAddr := evmc.Address{0x2}
+	targetAddr := evmc.Address{0x3}
+
+	cases := []struct {
+		// end is the code run by the target after the revert to the snapshot
+		end   string
+		value evmc.Hash
+	}{
+		// the revert of the call discards the revert to the snapshot
+		{revertCode(nil), evmc.Hash{31: 0x1}},
+		{"00", evmc.Hash{}},
+	}
+
+	for _, c := range cases {
+		vm := newVMCheatcode(nil)
+		vm.reset()
+		transition := state.NewTransition(state.WithCheatcode(vm))
+
+		// the test writes the slot 0 after the snapshot and calls the target
+		testCode, err := hex.DecodeString("6001600055" + callCode(targetAddr, nil, 0x0) + "00")
+		assert.NoError(t, err)
+		transition.Txn().SetCode(testAddr, testCode)
+
+		output := callVM(t, transition, "snapshot()")
+		assert.True(t, output.Success)
+		id := new(big.Int).SetBytes(output.ReturnValue)
+
+		// the target reverts to a snapshot taken before the call of the test
+		targetCode, err := hex.DecodeString(callCode(vmAddr, encodeVMCall(t, "revertTo(uint256)", id), 0x0) + c.end)
+		assert.NoError(t, err)
+		transition.Txn().SetCode(targetAddr, targetCode)
+
+		output = transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &testAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.True(t, output.Success)
+		assert.Equal(t, c.value, transition.Txn().GetState(testAddr, evmc.Hash{}))
+	}
+}
+
+// decodeVMOutput decodes the output of a call to the vm cheatcode with the given method
+func decodeVMOutput(t *testing.T, signature string, output []byte) map[string]interface{} {
+	method, err := abi.NewMethod(signature)
+	assert.NoError(t, err)
+
+	res, err := method.Decode(output)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestVMCheatcode_Record(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithTracer(vm))
+
+	// write the slot 2 and read the slot 3
+	contractAddr := evmc.Address{0x3}
+	code, err := hex.DecodeString("600160025560035450")
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, code)
+
+	callContract := func() {
+		assert.True(t, transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &contractAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		}).Success)
+	}
+	accesses := func(addr ethgo.Address) map[string]interface{} {
+		output := callVM(t, transition, "accesses(address)", addr)
+		assert.True(t, output.Success)
+
+		return decodeVMOutput(t, "accesses(address) returns (bytes32[] reads, bytes32[] writes)", output.ReturnValue)
+	}
+
+	// the accesses are not recorded before record is called
+	callContract()
+	assert.Empty(t, accesses(ethgo.Address(contractAddr))["reads"])
+
+	assert.True(t, callVM(t, transition, "record()").Success)
+	callContract()
+
+	res := accesses(ethgo.Address(contractAddr))
+	assert.Equal(t, [][32]byte{{31: 0x3}}, res["reads"])
+	assert.Equal(t, [][32]byte{{31: 0x2}}, res["writes"])
+
+	res = accesses(ethgo.Address{0x5})
+	assert.Empty(t, res["reads"])
+	assert.Empty(t, res["writes"])
+}
+
+func TestVMCheatcode_RecordLogs(t *testing.T) {
+	vm := newVMCheatcode(nil)
+	vm.reset()
+	transition := state.NewTransition(state.WithCheatcode(vm), state.WithHook(vm))
+
+	contractAddr := evmc.Address{0x3}
+	code, err := hex.DecodeString(logCode([]evmc.Hash{{0x1}, {0x2}}, []byte{31: 0x1}))
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, code)
+
+	callContract := func() {
+		assert.True(t, transition.Apply(&state.Message{
+			From:     evmc.Address{0x1},
+			To:       &contractAddr,
+			Gas:      1000000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		}).Success)
+	}
+	recordedLogs := func() []map[string]interface{} {
+		output := callVM(t, transition, "getRecordedLogs()")
+		assert.True(t, output.Success)
+
+		res := decodeVMOutput(t, "getRecordedLogs() returns (tuple(bytes32[] topics, bytes data, address emitter)[] logs)", output.ReturnValue)
+		return res["logs"].([]map[string]interface{})
+	}
+
+	// the logs are not recorded before recordLogs is called
+	callContract()
+	assert.Empty(t, recordedLogs())
+
+	assert.True(t, callVM(t, transition, "recordLogs()").Success)
+	callContract()
+	callContract()
+
+	logs := recordedLogs()
+	assert.Len(t, logs, 2)
+	assert.Equal(t, [][32]byte{{0x1}, {0x2}}, logs[0]["topics"])
+	assert.Equal(t, []byte{31: 0x1}, logs[0]["data"])
+	assert.Equal(t, ethgo.Address(contractAddr), logs[0]["emitter"])
+
+	// the logs are only returned once
+	assert.Empty(t, recordedLogs())
+}
+
+func TestVMCheatcode_Env(t *testing.T) {
+	t.Setenv("GREENHOUSE_UINT", "0x10")
+	t.Setenv("GREENHOUSE_ADDRESS", "0x0000000000000000000000000000000000000005")
+	t.Setenv("GREENHOUSE_STRING", "value")
+	t.Setenv("GREENHOUSE_SECRET", "secret")
+
+	project := &Project{
+		config: &Config{
+			Permissions: &Permissions{
+				Env: []string{"GREENHOUSE_UINT", "GREENHOUSE_ADDRESS", "GREENHOUSE_STRING", "GREENHOUSE_UNSET"},
+			},
+		},
+	}
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(project)))
+
+	output := callVM(t, transition, "envUint(string)", "GREENHOUSE_UINT")
+	assert.True(t, output.Success)
+	assert.Equal(t, byte(0x10), output.ReturnValue[31])
+
+	output = callVM(t, transition, "envAddress(string)", "GREENHOUSE_ADDRESS")
+	assert.True(t, output.Success)
+	assert.Equal(t, byte(0x5), output.ReturnValue[31])
+
+	output = callVM(t, transition, "envString(string)", "GREENHOUSE_STRING")
+	assert.True(t, output.Success)
+	assert.Equal(t, "value", decodeVMOutput(t, "envString(string) returns (string value)", output.ReturnValue)["value"])
+
+	// the default value is returned if the variable is not set
+	output = callVM(t, transition, "envOr(string,uint256)", "GREENHOUSE_UNSET", big.NewInt(7))
+	assert.True(t, output.Success)
+	assert.Equal(t, byte(7), output.ReturnValue[31])
+
+	output = callVM(t, transition, "envOr(string,string)", "GREENHOUSE_STRING", "default")
+	assert.True(t, output.Success)
+	assert.Equal(t, "value", decodeVMOutput(t, "envOr(string,string) returns (string value)", output.ReturnValue)["value"])
+
+	output = callVM(t, transition, "envUint(string)", "GREENHOUSE_UNSET")
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: envUint: environment variable 'GREENHOUSE_UNSET' not found", decodeRevert(output.ReturnValue))
+
+	output = callVM(t, transition, "envUint(string)", "GREENHOUSE_STRING")
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: envUint: environment variable 'GREENHOUSE_STRING' is not an uint256: 'value'", decodeRevert(output.ReturnValue))
+
+	// the variables must be listed in the permissions
+	output = callVM(t, transition, "envOr(string,string)", "GREENHOUSE_SECRET", "default")
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: envOr: environment variable 'GREENHOUSE_SECRET' is not in permissions.env", decodeRevert(output.ReturnValue))
+}
+
+func TestVMCheatcode_Files(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-vm-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// the paths are relative to the project
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(tmpDir))
+	defer os.Chdir(cwd)
+
+	assert.NoError(t, os.MkdirAll("fixtures", 0755))
+	assert.NoError(t, ioutil.WriteFile("fixtures/data.txt", []byte("a\nb\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile("secret.txt", []byte("secret"), 0644))
+
+	project := &Project{
+		config: &Config{
+			Permissions: &Permissions{
+				Read:  []string{"fixtures"},
+				Write: []string{"out"},
+			},
+		},
+	}
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(project)))
+
+	readString := func(signature string, path string) string {
+		output := callVM(t, transition, signature, path)
+		assert.True(t, output.Success)
+		return decodeVMOutput(t, signature+" returns (string data)", output.ReturnValue)["data"].(string)
+	}
+
+	assert.Equal(t, "a\nb\n", readString("readFile(string)", "fixtures/data.txt"))
+	assert.Equal(t, "a", readString("readLine(string)", "fixtures/data.txt"))
+	assert.Equal(t, "b", readString("readLine(string)", "./fixtures/data.txt"))
+	assert.Equal(t, "", readString("readLine(string)", "fixtures/data.txt"))
+
+	assert.True(t, callVM(t, transition, "writeFile(string,string)", "out/result.txt", "result").Success)
+	assert.Equal(t, "result", readString("readFile(string)", "out/result.txt"))
+
+	cases := []struct {
+		signature string
+		args      []interface{}
+		reason    string
+	}{
+		{
+			"readFile(string)",
+			[]interface{}{"secret.txt"},
+			"vm: readFile: path 'secret.txt' is not in permissions.read",
+		},
+		{
+			"readFile(string)",
+			[]interface{}{"fixtures/../secret.txt"},
+			"vm: readFile: path 'fixtures/../secret.txt' is not in permissions.read",
+		},
+		{
+			"readFile(string)",
+			[]interface{}{"../secret.txt"},
+			"vm: readFile: path '../secret.txt' is outside of the project",
+		},
+		{
+			"readLine(string)",
+			[]interface{}{"/etc/passwd"},
+			"vm: readLine: path '/etc/passwd' is not relative to the project",
+		},
+		{
+			"writeFile(string,string)",
+			[]interface{}{"fixtures/data.txt", "data"},
+			"vm: writeFile: path 'fixtures/data.txt' is not in permissions.write",
+		},
+	}
+	for _, c := range cases {
+		output := callVM(t, transition, c.signature, c.args...)
+		assert.False(t, output.Success)
+		assert.Equal(t, c.reason, decodeRevert(output.ReturnValue))
+	}
+}
+
+func TestVMCheatcode_FilesSymlink(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "greenhouse-vm-symlink")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// the project is a folder of tmpDir and the outside folder is not part of it
+	projectDir := filepath.Join(tmpDir, "project")
+	outsideDir := filepath.Join(tmpDir, "outside")
+	assert.NoError(t, os.MkdirAll(projectDir, 0755))
+	assert.NoError(t, os.MkdirAll(outsideDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(outsideDir, "passwd"), []byte("secret"), 0644))
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(projectDir))
+	defer os.Chdir(cwd)
+
+	assert.NoError(t, os.MkdirAll("fixtures", 0755))
+	assert.NoError(t, os.MkdirAll("out", 0755))
+	assert.NoError(t, ioutil.WriteFile("secret.txt", []byte("secret"), 0644))
+	assert.NoError(t, ioutil.WriteFile("fixtures/data.txt", []byte("data"), 0644))
+
+	links := map[string]string{
+		"fixtures/escape":  filepath.Join(outsideDir, "passwd"),
+		"fixtures/secret":  "../secret.txt",
+		"fixtures/data":    "data.txt",
+		"out/escape":       outsideDir,
+		"out/dangling.txt": filepath.Join(outsideDir, "new.txt"),
+	}
+	for link, target := range links {
+		assert.NoError(t, os.Symlink(target, link))
+	}
+
+	project := &Project{
+		config: &Config{
+			Permissions: &Permissions{
+				Read:  []string{"fixtures"},
+				Write: []string{"out"},
+			},
+		},
+	}
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(project)))
+
+	// the symlinks inside the allowed folders are followed
+	output := callVM(t, transition, "readFile(string)", "fixtures/data")
+	assert.True(t, output.Success)
+	assert.Equal(t, "data", decodeVMOutput(t, "readFile(string) returns (string data)", output.ReturnValue)["data"])
+
+	cases := []struct {
+		signature string
+		args      []interface{}
+		reason    string
+	}{
+		{
+			"readFile(string)",
+			[]interface{}{"fixtures/escape"},
+			"vm: readFile: path 'fixtures/escape' links outside of the project",
+		},
+		{
+			"readLine(string)",
+			[]interface{}{"fixtures/secret"},
+			"vm: readLine: path 'fixtures/secret' links to 'secret.txt', which is not in permissions.read",
+		},
+		{
+			"writeFile(string,string)",
+			[]interface{}{"out/escape/passwd", "data"},
+			"vm: writeFile: path 'out/escape/passwd' links outside of the project",
+		},
+		{
+			"writeFile(string,string)",
+			[]interface{}{"out/escape/new/file.txt", "data"},
+			"vm: writeFile: path 'out/escape/new/file.txt' links outside of the project",
+		},
+	}
+	for _, c := range cases {
+		output := callVM(t, transition, c.signature, c.args...)
+		assert.False(t, output.Success)
+		assert.Equal(t, c.reason, decodeRevert(output.ReturnValue))
+	}
+
+	// the dangling symlinks are not followed
+	assert.False(t, callVM(t, transition, "writeFile(string,string)", "out/dangling.txt", "data").Success)
+
+	// the files outside of the project are not modified
+	data, err := ioutil.ReadFile(filepath.Join(outsideDir, "passwd"))
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(data))
+
+	_, err = os.Stat(filepath.Join(outsideDir, "new.txt"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(outsideDir, "new"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVMCheatcode_FFI(t *testing.T) {
+	project := &Project{
+		config: &Config{
+			Permissions: &Permissions{},
+		},
+	}
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(project)))
+
+	output := callVM(t, transition, "ffi(string[])", []string{"echo", "hello"})
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: ffi: local commands are not allowed, set permissions.ffi", decodeRevert(output.ReturnValue))
+
+	project.config.Permissions.FFI = true
+
+	ffi := func(args ...string) []byte {
+		output := callVM(t, transition, "ffi(string[])", args)
+		assert.True(t, output.Success)
+		return decodeVMOutput(t, "ffi(string[]) returns (bytes output)", output.ReturnValue)["output"].([]byte)
+	}
+	assert.Equal(t, []byte("hello"), ffi("echo", "hello"))
+
+	// the hex output is decoded
+	assert.Equal(t, []byte{0x1, 0x2}, ffi("echo", "0x0102"))
+
+	output = callVM(t, transition, "ffi(string[])", []string{"false"})
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: ffi: command 'false' failed: exit status 1", decodeRevert(output.ReturnValue))
+}
+
+func TestVMCheatcode_Crypto(t *testing.T) {
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(nil)))
+
+	mnemonic := "test test test test test test test test test test test junk"
+	output := callVM(t, transition, "deriveKey(string,uint32)", mnemonic, uint32(0))
+	assert.True(t, output.Success)
+	assert.Equal(t, "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", hex.EncodeToString(output.ReturnValue))
+	pk := new(big.Int).SetBytes(output.ReturnValue)
+
+	output = callVM(t, transition, "addr(uint256)", pk)
+	assert.True(t, output.Success)
+	assert.Equal(t, ethgo.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266").Bytes(), output.ReturnValue[12:])
+
+	output = callVM(t, transition, "addr(uint256)", big.NewInt(0))
+	assert.False(t, output.Success)
+	assert.Equal(t, "vm: addr: invalid private key", decodeRevert(output.ReturnValue))
+
+	// the signature is accepted by the ecrecover precompile
+	digest := ethgo.Keccak256([]byte("message"))
+	output = callVM(t, transition, "sign(uint256,bytes32)", pk, digest)
+	assert.True(t, output.Success)
+	assert.Contains(t, []byte{27, 28}, output.ReturnValue[31])
+
+	ecrecover := evmc.Address{19: 0x1}
+	recovered := transition.Apply(&state.Message{
+		From:     evmc.Address{0x1},
+		To:       &ecrecover,
+		Gas:      1000000,
+		GasPrice: big.NewInt(0),
+		Value:    big.NewInt(0),
+		Input:    append(digest, output.ReturnValue...),
+	})
+	assert.True(t, recovered.Success)
+	assert.Equal(t, ethgo.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266").Bytes(), recovered.ReturnValue[12:])
+}
+
+func TestVMCheatcode_EIP712(t *testing.T) {
+	transition := state.NewTransition(state.WithCheatcode(newVMCheatcode(nil)))
+
+	contract := ethgo.Address{0x1}
+	output := callVM(t, transition, "domainSeparator(string,string,uint256,address)", "Token", "1", big.NewInt(1), contract)
+	assert.True(t, output.Success)
+
+	chainID := make([]byte, 32)
+	chainID[31] = 1
+	expected := ethgo.Keccak256(
+		ethgo.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		ethgo.Keccak256([]byte("Token")),
+		ethgo.Keccak256([]byte("1")),
+		chainID,
+		append(make([]byte, 12), contract.Bytes()...),
+	)
+	assert.Equal(t, expected, output.ReturnValue)
+
+	var separator, structHash [32]byte
+	copy(separator[:], output.ReturnValue)
+	copy(structHash[:], ethgo.Keccak256([]byte("struct")))
+
+	output = callVM(t, transition, "hashTypedData(bytes32,bytes32)", separator, structHash)
+	assert.True(t, output.Success)
+	assert.Equal(t, ethgo.Keccak256([]byte{0x19, 0x01}, separator[:], structHash[:]), output.ReturnValue)
+}
@@ -1,6 +1,7 @@
 package dag
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -9,6 +10,9 @@ type Dag struct {
 	once   sync.Once
 	vertex set
 
+	// order is the list of vertex in insertion order
+	order []Vertex
+
 	inbound  set
 	outbound set
 }
@@ -61,6 +65,14 @@ func (d *Dag) GetOutbound(v Vertex) (res []Vertex) {
 // AddVertex adds a new vertex on the DAG
 func (d *Dag) AddVertex(v Vertex) {
 	d.init()
+
+	k := v
+	if h, ok := v.(Hashable); ok {
+		k = h.Hash()
+	}
+	if !d.vertex.include(k) {
+		d.order = append(d.order, v)
+	}
 	d.vertex.add(v)
 }
 
@@ -120,6 +132,46 @@ func (d *Dag) FindComponents() [][]Vertex {
 	return result
 }
 
+// TopologicalSort returns the vertex of the graph sorted such that the source
+// of every edge comes before its destination. Vertex without a dependency
+// between them are returned in insertion order. It fails if the graph has a cycle.
+func (d *Dag) TopologicalSort() ([]Vertex, error) {
+	d.init()
+
+	inDegree := map[Vertex]int{}
+	for _, v := range d.order {
+		if vals, ok := d.inbound[v]; ok {
+			inDegree[v] = len(vals.(set))
+		}
+	}
+
+	res := []Vertex{}
+	done := map[Vertex]struct{}{}
+	for len(res) != len(d.order) {
+		var next Vertex
+		found := false
+		for _, v := range d.order {
+			if _, ok := done[v]; ok {
+				continue
+			}
+			if inDegree[v] == 0 {
+				next, found = v, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("the graph has a cycle")
+		}
+
+		done[next] = struct{}{}
+		res = append(res, next)
+		for _, v := range d.GetOutbound(next) {
+			inDegree[v]--
+		}
+	}
+	return res, nil
+}
+
 type set map[interface{}]interface{}
 
 func (s set) add(v Vertex) {
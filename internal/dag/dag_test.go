@@ -37,3 +37,24 @@ func TestDag_FindComponents(t *testing.T) {
 
 	d.FindComponents()
 }
+
+func TestDag_TopologicalSort(t *testing.T) {
+	d := &Dag{}
+	d.AddVertex(1)
+	d.AddVertex(2)
+	d.AddVertex(3)
+	d.AddVertex(4)
+
+	d.AddEdge(Edge{Src: 3, Dst: 1})
+	d.AddEdge(Edge{Src: 2, Dst: 1})
+	d.AddEdge(Edge{Src: 3, Dst: 2})
+
+	res, err := d.TopologicalSort()
+	assert.NoError(t, err)
+	assert.Equal(t, []Vertex{3, 2, 1, 4}, res)
+
+	// cycle
+	d.AddEdge(Edge{Src: 1, Dst: 3})
+	_, err = d.TopologicalSort()
+	assert.Error(t, err)
+}
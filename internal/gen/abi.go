@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/umbracle/ethgo/abi"
+)
+
+// Argument is an input or output of an abi entry. Unlike abi.ArgumentStr
+// it keeps the internal type reported by the compiler (i.e. struct names).
+type Argument struct {
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	InternalType string      `json:"internalType"`
+	Indexed      bool        `json:"indexed"`
+	Components   []*Argument `json:"components"`
+}
+
+// ABIType returns the abi type of the argument
+func (a *Argument) ABIType() (*abi.Type, error) {
+	return abi.NewTypeFromArgument(a.argumentStr())
+}
+
+func (a *Argument) argumentStr() *abi.ArgumentStr {
+	arg := &abi.ArgumentStr{
+		Name:    a.Name,
+		Type:    a.Type,
+		Indexed: a.Indexed,
+	}
+	for _, c := range a.Components {
+		arg.Components = append(arg.Components, c.argumentStr())
+	}
+	return arg
+}
+
+// Entry is an element of the abi (function, event, error, constructor...)
+type Entry struct {
+	Type            string      `json:"type"`
+	Name            string      `json:"name"`
+	Inputs          []*Argument `json:"inputs"`
+	Outputs         []*Argument `json:"outputs"`
+	StateMutability string      `json:"stateMutability"`
+	Anonymous       bool        `json:"anonymous"`
+}
+
+// IsConst returns whether the function does not modify the state
+func (e *Entry) IsConst() bool {
+	return e.StateMutability == "view" || e.StateMutability == "pure"
+}
+
+// IsPayable returns whether the function accepts value
+func (e *Entry) IsPayable() bool {
+	return e.StateMutability == "payable"
+}
+
+// Signature returns the canonical signature of the entry (i.e. transfer(address,uint256))
+func (e *Entry) Signature() (string, error) {
+	types := []string{}
+	for _, input := range e.Inputs {
+		typ, err := input.ABIType()
+		if err != nil {
+			return "", err
+		}
+		types = append(types, strings.Replace(typ.String(), "tuple", "", -1))
+	}
+	return e.Name + "(" + strings.Join(types, ",") + ")", nil
+}
+
+// ParseABI parses the entries of a json abi
+func ParseABI(raw string) ([]*Entry, error) {
+	var entries []*Entry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse abi: %v", err)
+	}
+	return entries, nil
+}
+
+// filterEntries returns the entries of the given type
+func filterEntries(entries []*Entry, typ string) []*Entry {
+	res := []*Entry{}
+	for _, e := range entries {
+		if e.Type == typ || (typ == "function" && e.Type == "") {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// exportName returns the name with the first letter in upper case
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return "X"
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// overloadedNames returns a unique name for each entry following the same
+// scheme as ethgo: the second entry with a repeated name gets the suffix 0,
+// the third the suffix 1...
+func overloadedNames(entries []*Entry) []string {
+	names := []string{}
+	used := map[string]struct{}{}
+	for _, e := range entries {
+		name := e.Name
+		for indx := 0; ; indx++ {
+			if _, ok := used[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s%d", e.Name, indx)
+		}
+		used[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
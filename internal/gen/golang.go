@@ -0,0 +1,337 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/greenhouse/bindings"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+// goReserved are the identifiers used by the generated code that
+// cannot be used as names of arguments
+var goReserved = map[string]struct{}{
+	"c": {}, "out": {}, "ok": {}, "err": {}, "backend": {}, "from": {}, "value": {},
+	"bin": {}, "contract": {}, "receipt": {}, "log": {}, "res": {}, "event": {},
+	"abi": {}, "ethgo": {}, "big": {}, "fmt": {}, "hex": {}, "bindings": {},
+}
+
+// goMethodReserved are the method names of the binding that cannot be generated
+var goMethodReserved = map[string]struct{}{
+	"Addr": {}, "SetFrom": {},
+}
+
+type goArg struct {
+	Name string
+	Key  string
+	Type string
+}
+
+type goMethod struct {
+	Name      string
+	Signature string
+	Const     bool
+	Payable   bool
+	Inputs    []*goArg
+	Outputs   []*goArg
+}
+
+type goEvent struct {
+	Name      string
+	ABIName   string
+	Signature string
+	Fields    []*goArg
+}
+
+type goContract struct {
+	Package            string
+	Name               string
+	ABI                string
+	Bin                string
+	Constructor        []*goArg
+	ConstructorPayable bool
+	Methods            []*goMethod
+	Events             []*goEvent
+}
+
+// goType returns the Go type used by ethgo to encode and decode the abi type
+func goType(t *abi.Type) string {
+	switch t.Kind() {
+	case abi.KindTuple:
+		return "map[string]interface{}"
+	case abi.KindSlice:
+		return "[]" + goType(t.Elem())
+	case abi.KindArray:
+		return fmt.Sprintf("[%d]%s", t.Size(), goType(t.Elem()))
+	case abi.KindBytes:
+		return "[]byte"
+	case abi.KindFixedBytes:
+		return fmt.Sprintf("[%d]byte", t.Size())
+	case abi.KindFunction:
+		return "[24]byte"
+	}
+	return t.GoType().String()
+}
+
+// goParamName returns a valid Go identifier for an argument
+func goParamName(name string, indx int, prefix string) string {
+	if name == "" {
+		return prefix + strconv.Itoa(indx)
+	}
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return prefix + strconv.Itoa(indx)
+	}
+	if _, ok := goReserved[name]; ok || token.Lookup(name).IsKeyword() {
+		name += "_"
+	}
+	return name
+}
+
+func newGoArgs(args []*Argument, prefix string, event bool) ([]*goArg, error) {
+	res := []*goArg{}
+	for indx, arg := range args {
+		typ, err := arg.ABIType()
+		if err != nil {
+			return nil, err
+		}
+		goTyp := goType(typ)
+		if event && arg.Indexed && !bindings.IsTopicType(typ) {
+			// only the hash of the value is stored in the topic
+			goTyp = "ethgo.Hash"
+		}
+
+		key := arg.Name
+		if key == "" {
+			key = strconv.Itoa(indx)
+		}
+		var name string
+		if event {
+			// fields of the event struct
+			name = exportName(arg.Name)
+			if name == "" {
+				name = exportName(prefix) + strconv.Itoa(indx)
+			}
+			if name == "Raw" {
+				name += "_"
+			}
+		} else {
+			name = goParamName(arg.Name, indx, prefix)
+		}
+		res = append(res, &goArg{
+			Name: name,
+			Key:  key,
+			Type: goTyp,
+		})
+	}
+	return res, nil
+}
+
+func newGoContract(pkg string, contract *state.Contract) (*goContract, error) {
+	entries, err := ParseABI(contract.Abi)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &goContract{
+		Package: pkg,
+		Name:    exportName(contract.Name),
+		ABI:     strconv.Quote(contract.Abi),
+		Bin:     contract.Bin,
+	}
+
+	if ctors := filterEntries(entries, "constructor"); len(ctors) != 0 {
+		if c.Constructor, err = newGoArgs(ctors[0].Inputs, "arg", false); err != nil {
+			return nil, err
+		}
+		c.ConstructorPayable = ctors[0].IsPayable()
+	}
+
+	methods := filterEntries(entries, "function")
+	for indx, name := range overloadedNames(methods) {
+		entry := methods[indx]
+
+		sig, err := entry.Signature()
+		if err != nil {
+			return nil, err
+		}
+		method := &goMethod{
+			Name:      exportName(name),
+			Signature: sig,
+			Const:     entry.IsConst(),
+			Payable:   entry.IsPayable(),
+		}
+		if _, ok := goMethodReserved[method.Name]; ok {
+			method.Name += "_"
+		}
+		if method.Inputs, err = newGoArgs(entry.Inputs, "arg", false); err != nil {
+			return nil, err
+		}
+		if method.Outputs, err = newGoArgs(entry.Outputs, "retval", false); err != nil {
+			return nil, err
+		}
+		// the outputs are named results and cannot repeat the name of an input
+		used := map[string]struct{}{}
+		for _, input := range method.Inputs {
+			used[input.Name] = struct{}{}
+		}
+		for _, output := range method.Outputs {
+			for {
+				if _, ok := used[output.Name]; !ok {
+					break
+				}
+				output.Name += "_"
+			}
+			used[output.Name] = struct{}{}
+		}
+		c.Methods = append(c.Methods, method)
+	}
+
+	events := filterEntries(entries, "event")
+	for indx, name := range overloadedNames(events) {
+		entry := events[indx]
+		if entry.Anonymous {
+			continue
+		}
+
+		sig, err := entry.Signature()
+		if err != nil {
+			return nil, err
+		}
+		event := &goEvent{
+			Name:      exportName(name),
+			ABIName:   name,
+			Signature: sig,
+		}
+		if event.Fields, err = newGoArgs(entry.Inputs, "arg", true); err != nil {
+			return nil, err
+		}
+		c.Events = append(c.Events, event)
+	}
+	return c, nil
+}
+
+// GenerateGo generates the Go bindings of the contract in the package pkg
+func GenerateGo(pkg string, contract *state.Contract) ([]byte, error) {
+	c, err := newGoContract(pkg, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, c); err != nil {
+		return nil, err
+	}
+	res, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the bindings of %s: %v", contract.Name, err)
+	}
+	return res, nil
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by greenhouse. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/greenhouse/bindings"
+)
+
+var (
+	_ = big.NewInt
+	_ = fmt.Errorf
+	_ = hex.DecodeString
+)
+
+// {{.Name}}ABI is the abi of the {{.Name}} contract
+var {{.Name}}ABI = abi.MustNewABI({{.ABI}})
+
+// {{.Name}}Bin is the creation bytecode of the {{.Name}} contract
+var {{.Name}}Bin = "{{.Bin}}"
+
+// {{.Name}} is a binding of the {{.Name}} contract
+type {{.Name}} struct {
+	c *bindings.Contract
+}
+
+// New{{.Name}} creates a binding of a deployed {{.Name}} contract
+func New{{.Name}}(addr ethgo.Address, backend bindings.Backend) *{{.Name}} {
+	return &{{.Name}}{c: bindings.NewContract(addr, {{.Name}}ABI, backend)}
+}
+{{if .Bin}}
+// Deploy{{.Name}} deploys a new {{.Name}} contract
+func Deploy{{.Name}}(backend bindings.Backend, from ethgo.Address{{if .ConstructorPayable}}, value *big.Int{{end}}{{range .Constructor}}, {{.Name}} {{.Type}}{{end}}) (*{{.Name}}, *bindings.Receipt, error) {
+	bin, err := hex.DecodeString({{.Name}}Bin)
+	if err != nil {
+		return nil, nil, err
+	}
+	contract, receipt, err := bindings.Deploy(backend, from, {{.Name}}ABI, bin, {{if .ConstructorPayable}}value{{else}}nil{{end}}{{range .Constructor}}, {{.Name}}{{end}})
+	if err != nil {
+		return nil, receipt, err
+	}
+	return &{{.Name}}{c: contract}, receipt, nil
+}
+{{end}}
+// Addr returns the address of the contract
+func (c *{{.Name}}) Addr() ethgo.Address {
+	return c.c.Addr()
+}
+
+// SetFrom sets the sender of the calls and transactions
+func (c *{{.Name}}) SetFrom(from ethgo.Address) {
+	c.c.SetFrom(from)
+}
+{{$contract := .Name}}
+{{range .Methods}}{{if .Const}}
+// {{.Name}} calls the {{.Signature}} method
+func (c *{{$contract}}) {{.Name}}({{range $i, $a := .Inputs}}{{if $i}}, {{end}}{{$a.Name}} {{$a.Type}}{{end}}) ({{range .Outputs}}{{.Name}} {{.Type}}, {{end}}err error) {
+	out, err := c.c.Call("{{.Signature}}"{{range .Inputs}}, {{.Name}}{{end}})
+	if err != nil {
+		return
+	}
+	{{if .Outputs}}var ok bool
+	{{end}}{{range .Outputs}}if {{.Name}}, ok = out["{{.Key}}"].({{.Type}}); !ok {
+		err = fmt.Errorf("failed to decode output '{{.Key}}'")
+		return
+	}
+	{{end}}return
+}
+{{else}}
+// {{.Name}} sends a transaction to the {{.Signature}} method
+func (c *{{$contract}}) {{.Name}}({{if .Payable}}value *big.Int{{if .Inputs}}, {{end}}{{end}}{{range $i, $a := .Inputs}}{{if $i}}, {{end}}{{$a.Name}} {{$a.Type}}{{end}}) (*bindings.Receipt, error) {
+	return c.c.Txn("{{.Signature}}", {{if .Payable}}value{{else}}nil{{end}}{{range .Inputs}}, {{.Name}}{{end}})
+}
+{{end}}{{end}}
+{{range .Events}}
+// {{$contract}}{{.Name}}Event is the {{.Signature}} event
+type {{$contract}}{{.Name}}Event struct {
+	{{range .Fields}}{{.Name}} {{.Type}}
+	{{end}}Raw *ethgo.Log
+}
+
+// Parse{{.Name}}Event decodes a {{.Signature}} log
+func (c *{{$contract}}) Parse{{.Name}}Event(log *ethgo.Log) (*{{$contract}}{{.Name}}Event, error) {
+	res, err := bindings.DecodeEvent({{$contract}}ABI.Events["{{.ABIName}}"], log)
+	if err != nil {
+		return nil, err
+	}
+	event := &{{$contract}}{{.Name}}Event{Raw: log}
+	{{if .Fields}}var ok bool
+	{{end}}{{range .Fields}}if event.{{.Name}}, ok = res["{{.Key}}"].({{.Type}}); !ok {
+		return nil, fmt.Errorf("failed to decode field '{{.Key}}'")
+	}
+	{{end}}return event, nil
+}
+{{end}}`))
@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"encoding/hex"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestGenerateGo(t *testing.T) {
+	data, err := ioutil.ReadFile("./fixtures/token.abi")
+	assert.NoError(t, err)
+
+	contract := &state.Contract{
+		Name: "Token",
+		Abi:  string(data),
+		Bin:  "6000",
+	}
+	res, err := GenerateGo("token", contract)
+	assert.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "token.go", res, 0)
+	assert.NoError(t, err)
+
+	code := string(res)
+	assert.Contains(t, code, "func DeployToken(backend bindings.Backend, from ethgo.Address, name string, supply *big.Int)")
+	assert.Contains(t, code, "func (c *Token) BalanceOf(account ethgo.Address) (retval0 *big.Int, err error)")
+	assert.Contains(t, code, "func (c *Token) Transfer0(value *big.Int, to ethgo.Address) (*bindings.Receipt, error)")
+	assert.Contains(t, code, "func (c *Token) Info(id uint8) (id_ uint8, owners []ethgo.Address, pos map[string]interface{}, err error)")
+	assert.Contains(t, code, "func (c *Token) ParseTransferEvent(log *ethgo.Log) (*TokenTransferEvent, error)")
+}
+
+// goRunMain is the program that uses the bindings of the token
+// against the simulated backend
+const goRunMain = `package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/greenhouse/bindings"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	backend := bindings.NewSimulatedBackend()
+	from := ethgo.Address{0x1}
+
+	token, _, err := DeployToken(backend, from, "token", big.NewInt(1))
+	if err != nil {
+		return err
+	}
+	balance, err := token.BalanceOf(from)
+	if err != nil {
+		return err
+	}
+	receipt, err := token.Transfer(ethgo.Address{0x2}, big.NewInt(1))
+	if err != nil {
+		return err
+	}
+	if len(receipt.Logs) != 1 {
+		return fmt.Errorf("expected one log, got %d", len(receipt.Logs))
+	}
+	event, err := token.ParseTransferEvent(receipt.Logs[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(balance, event.From, event.To, event.Value)
+	return nil
+}
+`
+
+func TestGenerateGo_Run(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+
+	data, err := ioutil.ReadFile("./fixtures/token.abi")
+	assert.NoError(t, err)
+
+	// the runtime code emits Transfer(caller, <first argument>, 7) and returns 7 to any call
+	event := ethgo.Keccak256([]byte("Transfer(address,address,uint256)"))
+	runtime := "6007600052" + "600435" + "33" + "7f" + hex.EncodeToString(event) + "60206000a3" + "60206000f3"
+	bin := fmt.Sprintf("60%02x80600b6000396000f3", len(runtime)/2) + runtime
+
+	res, err := GenerateGo("main", &state.Contract{
+		Name: "Token",
+		Abi:  string(data),
+		Bin:  bin,
+	})
+	assert.NoError(t, err)
+
+	// the program is built inside the module to use its dependencies
+	tmpDir, err := ioutil.TempDir(".", "_gentest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "token.go"), res, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(goRunMain), 0644))
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+	assert.Equal(t, "7 0x0100000000000000000000000000000000000000 0x0200000000000000000000000000000000000000 7\n", string(output))
+}
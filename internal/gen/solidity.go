@@ -0,0 +1,256 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	// customErrorsVersion is the first version of the compiler with custom errors
+	customErrorsVersion = version.Must(version.NewVersion("0.8.4"))
+
+	// abicoderVersion is the first version of the compiler with the abicoder pragma
+	abicoderVersion = version.Must(version.NewVersion("0.7.5"))
+
+	// abicoderV2Version is the first version of the compiler that uses
+	// the abi coder v2 by default
+	abicoderV2Version = version.Must(version.NewVersion("0.8.0"))
+)
+
+type solStruct struct {
+	Name   string
+	Fields []string
+}
+
+// solInterface collects the declarations of the generated interface
+type solInterface struct {
+	structs     []*solStruct
+	structNames map[string]string
+}
+
+// structName returns the name of the struct declared in the interface for
+// the internal type (i.e. struct Token.Position) of a tuple argument
+func (s *solInterface) structName(internalType string) string {
+	qualified := strings.TrimPrefix(internalType, "struct ")
+	if indx := strings.Index(qualified, "["); indx != -1 {
+		qualified = qualified[:indx]
+	}
+	if name, ok := s.structNames[qualified]; ok {
+		return name
+	}
+
+	name := qualified
+	if indx := strings.LastIndex(name, "."); indx != -1 {
+		name = name[indx+1:]
+	}
+	// structs with the same name in different contracts use the full name
+	for _, used := range s.structNames {
+		if used == name {
+			name = strings.Replace(qualified, ".", "_", -1)
+			break
+		}
+	}
+	if name == "" {
+		name = fmt.Sprintf("Struct%d", len(s.structNames))
+	}
+	s.structNames[qualified] = name
+	return name
+}
+
+// typeName returns the Solidity type of the argument and declares the
+// structs it uses
+func (s *solInterface) typeName(arg *Argument) (string, error) {
+	if !strings.HasPrefix(arg.Type, "tuple") {
+		if arg.Type == "function" && strings.HasPrefix(arg.InternalType, "function ") {
+			return arg.InternalType, nil
+		}
+		// enums, contracts and user defined value types are
+		// declared with their abi type
+		return arg.Type, nil
+	}
+
+	suffix := strings.TrimPrefix(arg.Type, "tuple")
+	if !strings.HasPrefix(arg.InternalType, "struct ") {
+		return "", fmt.Errorf("tuple '%s' without struct type", arg.Name)
+	}
+
+	qualified := strings.TrimPrefix(arg.InternalType, "struct ")
+	if indx := strings.Index(qualified, "["); indx != -1 {
+		qualified = qualified[:indx]
+	}
+	if name, ok := s.structNames[qualified]; ok {
+		return name + suffix, nil
+	}
+
+	// declare first the structs of the fields
+	fields := []string{}
+	for indx, c := range arg.Components {
+		typ, err := s.typeName(c)
+		if err != nil {
+			return "", err
+		}
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("field%d", indx)
+		}
+		fields = append(fields, typ+" "+name)
+	}
+
+	name := s.structName(arg.InternalType)
+	s.structs = append(s.structs, &solStruct{
+		Name:   name,
+		Fields: fields,
+	})
+	return name + suffix, nil
+}
+
+// isReferenceType returns whether the argument requires a data location
+func isReferenceType(arg *Argument) bool {
+	return arg.Type == "string" || arg.Type == "bytes" ||
+		strings.HasSuffix(arg.Type, "]") || strings.HasPrefix(arg.Type, "tuple")
+}
+
+// params returns the list of parameters of a function, event or error.
+// location is the data location of the reference types if any.
+func (s *solInterface) params(args []*Argument, location string, event bool) (string, error) {
+	res := []string{}
+	for _, arg := range args {
+		typ, err := s.typeName(arg)
+		if err != nil {
+			return "", err
+		}
+		if location != "" && isReferenceType(arg) {
+			typ += " " + location
+		}
+		if event && arg.Indexed {
+			typ += " indexed"
+		}
+		if arg.Name != "" {
+			typ += " " + arg.Name
+		}
+		res = append(res, typ)
+	}
+	return strings.Join(res, ", "), nil
+}
+
+// uniqueOutputs returns the outputs of the function without the names
+// already used by the inputs, which would not compile
+func uniqueOutputs(e *Entry) []*Argument {
+	used := map[string]struct{}{}
+	for _, input := range e.Inputs {
+		used[input.Name] = struct{}{}
+	}
+	outputs := []*Argument{}
+	for _, output := range e.Outputs {
+		if _, ok := used[output.Name]; ok && output.Name != "" {
+			unnamed := *output
+			unnamed.Name = ""
+			output = &unnamed
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// GenerateInterface generates a Solidity interface with the functions,
+// events, errors and structs of the abi for the given compiler version
+func GenerateInterface(name string, abiStr string, solidityVersion string) ([]byte, error) {
+	solVersion, err := version.NewVersion(solidityVersion)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ParseABI(abiStr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &solInterface{
+		structNames: map[string]string{},
+	}
+	hasErrors := false
+
+	decls := []string{}
+	for _, e := range filterEntries(entries, "event") {
+		params, err := s.params(e.Inputs, "", true)
+		if err != nil {
+			return nil, err
+		}
+		decl := "event " + e.Name + "(" + params + ")"
+		if e.Anonymous {
+			decl += " anonymous"
+		}
+		decls = append(decls, decl+";")
+	}
+	for _, e := range filterEntries(entries, "error") {
+		params, err := s.params(e.Inputs, "", false)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, "error "+e.Name+"("+params+");")
+		hasErrors = true
+	}
+	for _, e := range entries {
+		switch e.Type {
+		case "receive":
+			decls = append(decls, "receive() external payable;")
+		case "fallback":
+			decl := "fallback() external"
+			if e.IsPayable() {
+				decl += " payable"
+			}
+			decls = append(decls, decl+";")
+		}
+	}
+	for _, e := range filterEntries(entries, "function") {
+		inputs, err := s.params(e.Inputs, "calldata", false)
+		if err != nil {
+			return nil, err
+		}
+		decl := "function " + e.Name + "(" + inputs + ") external"
+		if e.StateMutability != "" && e.StateMutability != "nonpayable" {
+			decl += " " + e.StateMutability
+		}
+		if len(e.Outputs) != 0 {
+			outputs, err := s.params(uniqueOutputs(e), "memory", false)
+			if err != nil {
+				return nil, err
+			}
+			decl += " returns (" + outputs + ")"
+		}
+		decls = append(decls, decl+";")
+	}
+
+	if hasErrors && solVersion.LessThan(customErrorsVersion) {
+		return nil, fmt.Errorf("interface %s has custom errors which require solidity %s but the project uses %s", name, customErrorsVersion, solidityVersion)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// SPDX-License-Identifier: UNLICENSED\n")
+	buf.WriteString("// Code generated by greenhouse. DO NOT EDIT.\n")
+	buf.WriteString("pragma solidity >=" + solVersion.String() + ";\n")
+	// the structs are encoded with the abi coder v2
+	if len(s.structs) != 0 && solVersion.LessThan(abicoderV2Version) {
+		if solVersion.LessThan(abicoderVersion) {
+			buf.WriteString("pragma experimental ABIEncoderV2;\n")
+		} else {
+			buf.WriteString("pragma abicoder v2;\n")
+		}
+	}
+	buf.WriteString("\n")
+	buf.WriteString("interface " + name + " {\n")
+	for _, st := range s.structs {
+		buf.WriteString("    struct " + st.Name + " {\n")
+		for _, field := range st.Fields {
+			buf.WriteString("        " + field + ";\n")
+		}
+		buf.WriteString("    }\n\n")
+	}
+	for _, decl := range decls {
+		buf.WriteString("    " + decl + "\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateInterface(t *testing.T) {
+	data, err := ioutil.ReadFile("./fixtures/token.abi")
+	assert.NoError(t, err)
+
+	res, err := GenerateInterface("IToken", string(data), "0.8.4")
+	assert.NoError(t, err)
+
+	code := string(res)
+	assert.Contains(t, code, "pragma solidity >=0.8.4;")
+	assert.Contains(t, code, "interface IToken {")
+	assert.Contains(t, code, "    struct Position {\n        int64 x;\n        bytes32 data;\n    }")
+	assert.Contains(t, code, "event Transfer(address indexed from, address indexed to, uint256 value);")
+	assert.Contains(t, code, "event Named(string indexed name, bytes);")
+	assert.Contains(t, code, "error InsufficientBalance(uint256 available, uint256 required);")
+	assert.Contains(t, code, "function balanceOf(address account) external view returns (uint256);")
+	assert.Contains(t, code, "function transfer(address to) external payable;")
+	assert.Contains(t, code, "function info(uint8 id) external pure returns (uint8, address[] memory owners, Position memory pos);")
+	assert.NotContains(t, code, "constructor")
+}
+
+func TestGenerateInterface_Structs(t *testing.T) {
+	abiStr := `[
+		{"type":"function","name":"set","stateMutability":"nonpayable","outputs":[],"inputs":[
+			{"name":"a","type":"tuple[]","internalType":"struct A.Pair[]","components":[
+				{"name":"inner","type":"tuple","internalType":"struct Inner","components":[{"name":"v","type":"uint256","internalType":"uint256"}]},
+				{"name":"b","type":"bytes","internalType":"bytes"}
+			]},
+			{"name":"b","type":"tuple","internalType":"struct B.Pair","components":[{"name":"x","type":"string","internalType":"string"}]},
+			{"name":"c","type":"uint8","internalType":"enum A.Kind"},
+			{"name":"d","type":"address","internalType":"contract IERC20"}
+		]}
+	]`
+	res, err := GenerateInterface("IA", abiStr, "0.8.0")
+	assert.NoError(t, err)
+
+	code := string(res)
+	assert.Contains(t, code, "pragma solidity >=0.8.0;")
+	assert.Contains(t, code, "struct Inner {\n        uint256 v;\n    }\n\n    struct Pair {\n        Inner inner;\n        bytes b;\n    }\n\n    struct B_Pair {\n        string x;\n    }")
+	assert.Contains(t, code, "function set(Pair[] calldata a, B_Pair calldata b, uint8 c, address d) external;")
+}
+
+func TestGenerateInterface_Version(t *testing.T) {
+	data, err := ioutil.ReadFile("./fixtures/token.abi")
+	assert.NoError(t, err)
+
+	// custom errors are not supported before 0.8.4
+	_, err = GenerateInterface("IToken", string(data), "0.8.3")
+	assert.Error(t, err)
+
+	abiStr := `[
+		{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[
+			{"name":"p","type":"tuple","internalType":"struct A.Pair","components":[{"name":"x","type":"uint256","internalType":"uint256"}]}
+		]},
+		{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"x","type":"uint256","internalType":"uint256"}],"outputs":[]}
+	]`
+
+	cases := []struct {
+		version string
+		pragmas string
+	}{
+		{"0.8.10", "pragma solidity >=0.8.10;\n\n"},
+		{"0.7.6", "pragma solidity >=0.7.6;\npragma abicoder v2;\n\n"},
+		{"0.6.12", "pragma solidity >=0.6.12;\npragma experimental ABIEncoderV2;\n\n"},
+	}
+	for _, c := range cases {
+		res, err := GenerateInterface("IA", abiStr, c.version)
+		assert.NoError(t, err)
+		assert.Contains(t, string(res), c.pragmas)
+	}
+
+	// the abi coder v2 is only required for the structs
+	res, err := GenerateInterface("IA", `[{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[],"outputs":[]}]`, "0.7.6")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(res), "abicoder")
+}
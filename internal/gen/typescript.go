@@ -0,0 +1,315 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/greenhouse/bindings"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+// tsHashPrefix is the prefix of the header line that stores the hash
+// of the abi and the bytecode
+const tsHashPrefix = "// abi-hash: "
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+type tsMethod struct {
+	Name      string
+	Signature string
+	Args      string
+	Return    string
+}
+
+type tsEvent struct {
+	Name      string
+	Signature string
+	Fields    string
+}
+
+type tsContract struct {
+	Hash     string
+	Name     string
+	Var      string
+	ABI      string
+	Bin      string
+	CtorArgs string
+	Methods  []*tsMethod
+	Events   []*tsEvent
+}
+
+// tsType returns the TypeScript type of the abi type. It follows the
+// conventions of abitype (viem): integers of up to 48 bits are numbers
+// and the rest bigints.
+func tsType(t *abi.Type) string {
+	switch t.Kind() {
+	case abi.KindBool:
+		return "boolean"
+	case abi.KindInt, abi.KindUInt:
+		if t.Size() <= 48 {
+			return "number"
+		}
+		return "bigint"
+	case abi.KindString:
+		return "string"
+	case abi.KindSlice, abi.KindArray:
+		return "readonly " + tsElemType(t.Elem()) + "[]"
+	case abi.KindTuple:
+		elems := t.TupleElems()
+		if len(elems) == 0 || elems[0].Name == "" {
+			types := []string{}
+			for _, elem := range elems {
+				types = append(types, tsType(elem.Elem))
+			}
+			return "readonly [" + strings.Join(types, ", ") + "]"
+		}
+		fields := []string{}
+		for _, elem := range elems {
+			fields = append(fields, tsKey(elem.Name)+": "+tsType(elem.Elem))
+		}
+		return "{ " + strings.Join(fields, "; ") + " }"
+	}
+	// address, bytes, fixed bytes and function
+	return "`0x${string}`"
+}
+
+// tsElemType returns the type of an array element, with parenthesis
+// if it is required to apply the array operator
+func tsElemType(t *abi.Type) string {
+	typ := tsType(t)
+	if strings.HasPrefix(typ, "readonly ") {
+		return "(" + typ + ")"
+	}
+	return typ
+}
+
+// tsKey returns the name as a valid property key of an object
+func tsKey(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// tsTuple returns the labeled tuple type of a list of arguments
+func tsTuple(args []*Argument) (string, error) {
+	elems := []string{}
+	for indx, arg := range args {
+		typ, err := arg.ABIType()
+		if err != nil {
+			return "", err
+		}
+		name := arg.Name
+		if !tsIdentifier.MatchString(name) {
+			name = "arg" + strconv.Itoa(indx)
+		}
+		elems = append(elems, name+": "+tsType(typ))
+	}
+	return "readonly [" + strings.Join(elems, ", ") + "]", nil
+}
+
+// tsReturn returns the return type of a list of outputs
+func tsReturn(args []*Argument) (string, error) {
+	switch len(args) {
+	case 0:
+		return "void", nil
+	case 1:
+		typ, err := args[0].ABIType()
+		if err != nil {
+			return "", err
+		}
+		return tsType(typ), nil
+	}
+	return tsTuple(args)
+}
+
+// tsEventFields returns the object type with the fields of an event
+func tsEventFields(args []*Argument) (string, error) {
+	fields := []string{}
+	for indx, arg := range args {
+		typ, err := arg.ABIType()
+		if err != nil {
+			return "", err
+		}
+		tsTyp := tsType(typ)
+		if arg.Indexed && !bindings.IsTopicType(typ) {
+			// only the hash of the value is stored in the topic
+			tsTyp = "`0x${string}`"
+		}
+		name := arg.Name
+		if name == "" {
+			name = "arg" + strconv.Itoa(indx)
+		}
+		fields = append(fields, tsKey(name)+": "+tsTyp)
+	}
+	if len(fields) == 0 {
+		return "{}", nil
+	}
+	return "{ " + strings.Join(fields, "; ") + " }", nil
+}
+
+// TypeScriptHash returns the hash of the abi and the bytecode of the contract
+// used to know whether the TypeScript bindings have to be generated again
+func TypeScriptHash(contract *state.Contract) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(contract.Abi)); err != nil {
+		return "", fmt.Errorf("failed to parse abi: %v", err)
+	}
+	// the bytecode is exported in the same module
+	buf.WriteString("\n" + contract.Bin)
+
+	hash := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// ReadTypeScriptHash returns the hash stored in the header of
+// a generated TypeScript module or an empty string if there is none
+func ReadTypeScriptHash(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 0; i < 2 && scanner.Scan(); i++ {
+		if line := scanner.Text(); strings.HasPrefix(line, tsHashPrefix) {
+			return strings.TrimPrefix(line, tsHashPrefix)
+		}
+	}
+	return ""
+}
+
+func newTsContract(contract *state.Contract) (*tsContract, error) {
+	entries, err := ParseABI(contract.Abi)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := TypeScriptHash(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	var abiBuf bytes.Buffer
+	if err := json.Indent(&abiBuf, []byte(contract.Abi), "", "  "); err != nil {
+		return nil, err
+	}
+
+	name := exportName(contract.Name)
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+
+	c := &tsContract{
+		Hash: hash,
+		Name: name,
+		Var:  string(r),
+		ABI:  strings.TrimSpace(abiBuf.String()),
+	}
+	if contract.Bin != "" {
+		c.Bin = "0x" + contract.Bin
+	}
+
+	if ctors := filterEntries(entries, "constructor"); len(ctors) != 0 {
+		if c.CtorArgs, err = tsTuple(ctors[0].Inputs); err != nil {
+			return nil, err
+		}
+	} else {
+		c.CtorArgs = "readonly []"
+	}
+
+	methods := filterEntries(entries, "function")
+	for indx, name := range overloadedNames(methods) {
+		entry := methods[indx]
+
+		sig, err := entry.Signature()
+		if err != nil {
+			return nil, err
+		}
+		method := &tsMethod{
+			Name:      exportName(name),
+			Signature: sig,
+		}
+		if method.Args, err = tsTuple(entry.Inputs); err != nil {
+			return nil, err
+		}
+		if method.Return, err = tsReturn(entry.Outputs); err != nil {
+			return nil, err
+		}
+		c.Methods = append(c.Methods, method)
+	}
+
+	events := filterEntries(entries, "event")
+	for indx, name := range overloadedNames(events) {
+		entry := events[indx]
+
+		sig, err := entry.Signature()
+		if err != nil {
+			return nil, err
+		}
+		event := &tsEvent{
+			Name:      exportName(name),
+			Signature: sig,
+		}
+		if event.Fields, err = tsEventFields(entry.Inputs); err != nil {
+			return nil, err
+		}
+		c.Events = append(c.Events, event)
+	}
+	return c, nil
+}
+
+// GenerateTypeScript generates the TypeScript module of the contract
+func GenerateTypeScript(contract *state.Contract) ([]byte, error) {
+	c, err := newTsContract(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tsTemplate.Execute(&buf, c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TypeScriptModule returns the name of the TypeScript module of the
+// contract, which is the name of the file without the extension
+func TypeScriptModule(contract *state.Contract) string {
+	return exportName(contract.Name)
+}
+
+// GenerateTypeScriptIndex generates a module that re-exports the modules of the contracts
+func GenerateTypeScriptIndex(contracts []*state.Contract) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by greenhouse. DO NOT EDIT.\n\n")
+	for _, contract := range contracts {
+		buf.WriteString("export * from \"./" + TypeScriptModule(contract) + "\";\n")
+	}
+	return buf.Bytes()
+}
+
+var tsTemplate = template.Must(template.New("ts").Parse(`// Code generated by greenhouse. DO NOT EDIT.
+` + tsHashPrefix + `{{.Hash}}
+
+/** Abi of the {{.Name}} contract */
+export const {{.Var}}Abi = {{.ABI}} as const;
+{{if .Bin}}
+/** Creation bytecode of the {{.Name}} contract */
+export const {{.Var}}Bytecode = "{{.Bin}}" as const;
+{{end}}
+/** Arguments of the {{.Name}} constructor */
+export type {{.Name}}ConstructorArgs = {{.CtorArgs}};
+{{$contract := .Name}}{{range .Methods}}
+/** Arguments of the {{.Signature}} method */
+export type {{$contract}}{{.Name}}Args = {{.Args}};
+
+/** Return value of the {{.Signature}} method */
+export type {{$contract}}{{.Name}}Return = {{.Return}};
+{{end}}{{range .Events}}
+/** Fields of the {{.Signature}} event */
+export type {{$contract}}{{.Name}}Event = {{.Fields}};
+{{end}}`))
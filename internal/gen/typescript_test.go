@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/greenhouse/internal/state"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	data, err := ioutil.ReadFile("./fixtures/token.abi")
+	assert.NoError(t, err)
+
+	contract := &state.Contract{
+		Name: "Token",
+		Abi:  string(data),
+		Bin:  "6000",
+	}
+	res, err := GenerateTypeScript(contract)
+	assert.NoError(t, err)
+
+	code := string(res)
+	assert.Contains(t, code, "export const tokenAbi = [")
+	assert.Contains(t, code, "] as const;")
+	assert.Contains(t, code, `export const tokenBytecode = "0x6000" as const;`)
+	assert.Contains(t, code, "export type TokenConstructorArgs = readonly [name: string, supply: bigint];")
+	assert.Contains(t, code, "export type TokenBalanceOfArgs = readonly [account: `0x${string}`];")
+	assert.Contains(t, code, "export type TokenBalanceOfReturn = bigint;")
+	assert.Contains(t, code, "export type TokenTransfer0Return = void;")
+	assert.Contains(t, code, "export type TokenInfoReturn = readonly [id: number, owners: readonly `0x${string}`[], pos: { x: bigint; data: `0x${string}` }];")
+	assert.Contains(t, code, "export type TokenTransferEvent = { from: `0x${string}`; to: `0x${string}`; value: bigint };")
+	assert.Contains(t, code, "export type TokenNamedEvent = { name: `0x${string}`; arg1: `0x${string}` };")
+
+	// the hash does not depend on the formatting of the abi
+	hash, err := TypeScriptHash(contract)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, ReadTypeScriptHash(res))
+
+	contract.Abi = " " + contract.Abi + "\n"
+	hash2, err := TypeScriptHash(contract)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+
+	// the bytecode is part of the module
+	contract.Bin = "6001"
+	hash2, err = TypeScriptHash(contract)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+
+	assert.Equal(t, "", ReadTypeScriptHash([]byte("export const a = 1;\n")))
+}
+
+func TestGenerateTypeScriptIndex(t *testing.T) {
+	contracts := []*state.Contract{
+		{Name: "Token"},
+		{Name: "token2"},
+	}
+	// the index imports the file of each module
+	assert.Equal(t, "Token", TypeScriptModule(contracts[0]))
+	assert.Equal(t, "Token2", TypeScriptModule(contracts[1]))
+
+	index := string(GenerateTypeScriptIndex(contracts))
+	assert.Contains(t, index, "export * from \"./Token\";\n")
+	assert.Contains(t, index, "export * from \"./Token2\";\n")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum/evmc/v10/bindings/go/evmc"
 	"github.com/umbracle/ethgo"
+	"github.com/umbracle/greenhouse/internal/runtime/evm"
 )
 
 type Config struct {
@@ -13,6 +14,9 @@ type Config struct {
 	Rev        evmc.Revision
 	State      Snapshot
 	Cheatcodes []Cheatcode
+	Hooks      []Hook
+	Mocks      []Mock
+	Tracer     Tracer
 }
 
 func DefaultConfig() *Config {
@@ -22,6 +26,8 @@ func DefaultConfig() *Config {
 		Rev:        evmc.Istanbul,
 		State:      &EmptyState{},
 		Cheatcodes: []Cheatcode{},
+		Hooks:      []Hook{},
+		Mocks:      []Mock{},
 	}
 	return c
 }
@@ -52,9 +58,34 @@ func WithState(state Snapshot) ConfigOption {
 	}
 }
 
+// CallContext is the context of a call to a cheatcode
+type CallContext struct {
+	// Transition is the transition that runs the call
+	Transition *Transition
+
+	// Caller is the address that calls the cheatcode
+	Caller evmc.Address
+
+	// Address is the address of the cheatcode
+	Address evmc.Address
+
+	// Value is the value sent with the call
+	Value *big.Int
+
+	// Depth is the depth of the call
+	Depth int
+
+	// Static signals whether the call cannot modify the state
+	Static bool
+}
+
+// Cheatcode is a contract implemented in Go. Run returns the output of
+// the call and the gas used. If it returns ErrExecutionReverted, the call
+// reverts with the output as the revert data, any other error consumes
+// all the gas of the call.
 type Cheatcode interface {
 	CanRun(addr evmc.Address) bool
-	Run(addr evmc.Address, input []byte)
+	Run(ctx *CallContext, input []byte) ([]byte, uint64, error)
 }
 
 func WithCheatcode(cheat Cheatcode) ConfigOption {
@@ -63,6 +94,45 @@ func WithCheatcode(cheat Cheatcode) ConfigOption {
 	}
 }
 
+// Hook is called before and after each call or contract creation of the
+// transition, except for the calls to the cheatcodes. BeforeCall can modify
+// the call before it runs (i.e. its caller) and AfterCall can replace the
+// result of the call. If AfterCall turns a successful call into an error,
+// the changes of the call are reverted. OnLog is called for each log
+// emitted and drops the log if it returns false.
+type Hook interface {
+	BeforeCall(t *Transition, c *Contract)
+	AfterCall(t *Transition, c *Contract, output []byte, err error) ([]byte, error)
+	OnLog(t *Transition, log *Log) bool
+}
+
+func WithHook(hook Hook) ConfigOption {
+	return func(c *Config) {
+		c.Hooks = append(c.Hooks, hook)
+	}
+}
+
+// Mock replaces the result of the calls it matches without running
+// their code. If revert is true, the call reverts with the output.
+type Mock interface {
+	MockCall(c *Contract) (output []byte, revert bool, ok bool)
+}
+
+func WithMock(mock Mock) ConfigOption {
+	return func(c *Config) {
+		c.Mocks = append(c.Mocks, mock)
+	}
+}
+
+// Tracer is notified of the storage reads (SLOAD) and writes (SSTORE)
+type Tracer = evm.Tracer
+
+func WithTracer(tracer Tracer) ConfigOption {
+	return func(c *Config) {
+		c.Tracer = tracer
+	}
+}
+
 func getHashDefault(n uint64) (res evmc.Hash) {
 	hash := ethgo.Keccak256([]byte(big.NewInt(int64(n)).String()))
 	copy(res[:], hash)
@@ -6,9 +6,16 @@ import (
 	"github.com/ethereum/evmc/v10/bindings/go/evmc"
 )
 
+// Tracer is notified of the storage accesses of the execution
+type Tracer interface {
+	OnStorageRead(addr evmc.Address, key evmc.Hash)
+	OnStorageWrite(addr evmc.Address, key evmc.Hash)
+}
+
 type EVM struct {
-	Host evmc.HostContext
-	Rev  evmc.Revision
+	Host   evmc.HostContext
+	Rev    evmc.Revision
+	Tracer Tracer
 }
 
 // Run implements the runtime interface
@@ -41,6 +48,7 @@ func (e *EVM) Run(typ evmc.CallKind, recipient evmc.Address, sender evmc.Address
 	s.gas = uint64(gas)
 	s.host = e.Host
 	s.rev = e.Rev
+	s.tracer = e.Tracer
 	s.bitmap.setCode(s.code)
 
 	ret, err := s.Run()
@@ -457,7 +457,11 @@ func opSload(c *state) {
 		return
 	}
 
-	val := c.host.GetStorage(c.Address, bigToHash(loc))
+	key := bigToHash(loc)
+	if c.tracer != nil {
+		c.tracer.OnStorageRead(c.Address, key)
+	}
+	val := c.host.GetStorage(c.Address, key)
 	loc.SetBytes(val[:])
 }
 
@@ -477,6 +481,9 @@ func opSStore(c *state) {
 
 	legacyGasMetering := !c.isRevision(evmc.Istanbul) && (c.isRevision(evmc.Petersburg) || !c.isRevision(evmc.Constantinople))
 
+	if c.tracer != nil {
+		c.tracer.OnStorageWrite(c.Address, key)
+	}
 	status := c.host.SetStorage(c.Address, key, val)
 	cost := uint64(0)
 
@@ -586,6 +593,16 @@ func opChainID(c *state) {
 	c.push1().SetBytes(chainID[:])
 }
 
+func opBaseFee(c *state) {
+	if !c.isRevision(evmc.London) {
+		c.exit(errOpCodeNotFound)
+		return
+	}
+
+	baseFee := c.host.GetTxContext().BaseFee
+	c.push1().SetBytes(baseFee[:])
+}
+
 func opOrigin(c *state) {
 	origin := c.host.GetTxContext().Origin
 	c.push1().SetBytes(origin[:])
@@ -161,6 +161,9 @@ const (
 	// SELFBALANCE returns the balance of the current account
 	SELFBALANCE = 0x47
 
+	// BASEFEE returns the current block's base fee
+	BASEFEE = 0x48
+
 	// POP pops a (u)int256 off the stack and discards it
 	POP = 0x50
 
@@ -331,6 +334,7 @@ var opCodeToString = map[OpCode]string{
 	SELFDESTRUCT:   "SELFDESTRUCT",
 	CHAINID:        "CHAINID",
 	SELFBALANCE:    "SELFBALANCE",
+	BASEFEE:        "BASEFEE",
 }
 
 func opCodesToString(from, to OpCode, str string) {
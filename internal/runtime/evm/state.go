@@ -47,7 +47,8 @@ type state struct {
 	code []byte
 	tmp  []byte
 
-	host evmc.HostContext
+	host   evmc.HostContext
+	tracer Tracer
 
 	Address evmc.Address
 	Caller  evmc.Address
@@ -90,6 +91,7 @@ func (c *state) reset() {
 	c.lastGasCost = 0
 	c.stop = false
 	c.err = nil
+	c.tracer = nil
 
 	// reset bitmap
 	c.bitmap.reset()
@@ -20,6 +20,9 @@ const (
 	TxGasContractCreation uint64 = 53000
 )
 
+// ErrExecutionReverted is returned when a call reverts
+var ErrExecutionReverted = evm.ErrExecutionReverted
+
 // getHashByNumber returns the hash function of a block number
 type GetHashByNumber = func(i uint64) evmc.Hash
 
@@ -42,6 +45,7 @@ type TxContext struct {
 	GasLimit   int64
 	ChainID    int64
 	Difficulty evmc.Hash
+	BaseFee    evmc.Hash
 }
 
 // NewExecutor creates a new executor
@@ -69,6 +73,12 @@ func (t *Transition) Txn() *Txn {
 	return t.txn
 }
 
+// Context returns the context of the transaction. The changes to the
+// context apply to the next operations of the transition.
+func (t *Transition) Context() *TxContext {
+	return &t.config.Ctx
+}
+
 // Write writes another transaction to the executor
 func (t *Transition) Write(msg *Message) (*Output, error) {
 	output, err := t.applyImpl(msg)
@@ -183,16 +193,17 @@ func (t *Transition) Apply(msg *Message) *Output {
 
 	var retValue []byte
 	var gasLeft int64
+	var address evmc.Address
 	var err error
 
 	if msg.IsContractCreation() {
-		address := createAddress(msg.From, t.txn.GetNonce(msg.From))
+		address = createAddress(msg.From, t.txn.GetNonce(msg.From))
 		contract := NewContractCreation(0, msg.From, address, value, msg.Gas, msg.Input)
-		retValue, gasLeft, _, err = t.applyCreate(contract)
+		retValue, gasLeft, address, err = t.Callx(contract)
 	} else {
 		t.txn.IncrNonce(msg.From)
 		c := NewContractCall(0, msg.From, *msg.To, value, msg.Gas, msg.Input)
-		retValue, gasLeft, _, err = t.applyCall(c, evmc.Call)
+		retValue, gasLeft, _, err = t.Callx(c)
 	}
 
 	output := &Output{
@@ -208,8 +219,9 @@ func (t *Transition) Apply(msg *Message) *Output {
 	}
 
 	// if the transaction created a contract, store the creation address in the receipt.
+	// The address depends on the nonce of the sender in the state, not on msg.Nonce.
 	if msg.To == nil {
-		output.ContractAddress = createAddress(msg.From, msg.Nonce)
+		output.ContractAddress = address
 	}
 
 	return output
@@ -245,9 +257,7 @@ func (t *Transition) run(c *Contract) ([]byte, int64, error) {
 	// try to run a cheatcode first
 	for _, cheat := range t.config.Cheatcodes {
 		if cheat.CanRun(c.CodeAddress) {
-			cheat.Run(c.CodeAddress, c.Input)
-			// Do not consume any gas with the cheat codes
-			return nil, int64(c.Gas), nil
+			return t.runCheatcode(cheat, c)
 		}
 	}
 	if t.isPrecompiled(c.CodeAddress) {
@@ -255,12 +265,33 @@ func (t *Transition) run(c *Contract) ([]byte, int64, error) {
 	}
 
 	evm := evm.EVM{
-		Host: t,
-		Rev:  t.config.Rev,
+		Host:   t,
+		Rev:    t.config.Rev,
+		Tracer: t.config.Tracer,
 	}
 	return evm.Run(c.Type, c.Address, c.Caller, c.Value, c.Input, int64(c.Gas), c.Depth, c.Static, c.CodeAddress)
 }
 
+func (t *Transition) runCheatcode(cheat Cheatcode, c *Contract) ([]byte, int64, error) {
+	ctx := &CallContext{
+		Transition: t,
+		Caller:     c.Caller,
+		Address:    c.Address,
+		Value:      c.Value,
+		Depth:      c.Depth,
+		Static:     c.Static,
+	}
+	retValue, gasUsed, err := cheat.Run(ctx, c.Input)
+	if gasUsed > c.Gas {
+		return nil, 0, errors.New("out of gas")
+	}
+	if err != nil && err != ErrExecutionReverted {
+		// consume all the gas like any other exceptional halt
+		return nil, 0, err
+	}
+	return retValue, int64(c.Gas - gasUsed), err
+}
+
 func (t *Transition) transfer(from, to evmc.Address, amount *big.Int) error {
 	if amount == nil {
 		return nil
@@ -284,9 +315,24 @@ func (t *Transition) applyCall(c *Contract, callType evmc.CallKind) ([]byte, int
 		}
 	}
 
+	// the mocked calls do not run the code nor consume gas
+	for _, mock := range t.config.Mocks {
+		if output, revert, ok := mock.MockCall(c); ok {
+			if revert {
+				if err := t.revertToSnapshot(snapshot); err != nil {
+					return nil, 0, evmc.Address{}, err
+				}
+				return output, int64(c.Gas), evmc.Address{}, ErrExecutionReverted
+			}
+			return output, int64(c.Gas), evmc.Address{}, nil
+		}
+	}
+
 	retValue, gasLeft, err := t.run(c)
 	if err != nil {
-		t.txn.RevertToSnapshot(snapshot)
+		if revertErr := t.revertToSnapshot(snapshot); revertErr != nil {
+			return nil, 0, evmc.Address{}, revertErr
+		}
 		if err != evm.ErrExecutionReverted {
 			// return value only allowed on error for reverted
 			retValue = nil
@@ -349,7 +395,9 @@ func (t *Transition) applyCreate(c *Contract) ([]byte, int64, evmc.Address, erro
 	retValue, gasLeft, err := t.run(c)
 
 	if err != nil {
-		t.txn.RevertToSnapshot(snapshot)
+		if revertErr := t.revertToSnapshot(snapshot); revertErr != nil {
+			return nil, 0, address, revertErr
+		}
 		if err != evm.ErrExecutionReverted {
 			retValue = nil
 		}
@@ -359,7 +407,9 @@ func (t *Transition) applyCreate(c *Contract) ([]byte, int64, evmc.Address, erro
 
 	if t.isRevision(evmc.SpuriousDragon) && len(retValue) > spuriousDragonMaxCodeSize {
 		// Contract size exceeds 'SpuriousDragon' size limit
-		t.txn.RevertToSnapshot(snapshot)
+		if err := t.revertToSnapshot(snapshot); err != nil {
+			return nil, 0, address, err
+		}
 		return nil, 0, address, errors.New("evm: max code size exceeded")
 	}
 
@@ -370,7 +420,9 @@ func (t *Transition) applyCreate(c *Contract) ([]byte, int64, evmc.Address, erro
 
 		// Out of gas creating the contract
 		if t.isRevision(evmc.Homestead) {
-			t.txn.RevertToSnapshot(snapshot)
+			if err := t.revertToSnapshot(snapshot); err != nil {
+				return nil, 0, address, err
+			}
 			gasLeft = 0
 		}
 
@@ -400,6 +452,7 @@ func (t *Transition) GetTxContext() evmc.TxContext {
 		GasLimit:   t.config.Ctx.GasLimit,
 		Difficulty: t.config.Ctx.Difficulty,
 		ChainID:    cc,
+		BaseFee:    t.config.Ctx.BaseFee,
 	}
 	return ctx
 }
@@ -409,6 +462,16 @@ func (t *Transition) GetBlockHash(number int64) (res evmc.Hash) {
 }
 
 func (t *Transition) EmitLog(addr evmc.Address, topics []evmc.Hash, data []byte) {
+	log := &Log{
+		Address: addr,
+		Topics:  topics,
+		Data:    data,
+	}
+	for _, hook := range t.config.Hooks {
+		if !hook.OnLog(t, log) {
+			return
+		}
+	}
 	t.txn.EmitLog(addr, topics, data)
 }
 
@@ -477,12 +540,61 @@ func (t *Transition) Call(kind evmc.CallKind,
 }
 
 func (t *Transition) Callx(c *Contract) ([]byte, int64, evmc.Address, error) {
+	hooks := t.config.Hooks
+	if t.isCheatcode(c) {
+		hooks = nil
+	}
+	if len(hooks) == 0 {
+		return t.callx(c)
+	}
+
+	for _, hook := range hooks {
+		hook.BeforeCall(t, c)
+	}
+
+	snapshot := t.txn.Snapshot()
+	retValue, gasLeft, addr, err := t.callx(c)
+
+	succeeded := err == nil
+	for _, hook := range hooks {
+		retValue, err = hook.AfterCall(t, c, retValue, err)
+	}
+	if succeeded && err != nil {
+		if revertErr := t.revertToSnapshot(snapshot); revertErr != nil {
+			return nil, 0, addr, revertErr
+		}
+	}
+	return retValue, gasLeft, addr, err
+}
+
+// revertToSnapshot reverts the state to the snapshot taken at the start of
+// a call. It fails if a cheatcode reverted to an earlier snapshot in the call.
+func (t *Transition) revertToSnapshot(snapshot int) error {
+	if err := t.txn.RevertToSnapshot(snapshot); err != nil {
+		return fmt.Errorf("failed to revert the state of the call: %v", err)
+	}
+	return nil
+}
+
+func (t *Transition) callx(c *Contract) ([]byte, int64, evmc.Address, error) {
 	if c.Type == evmc.Create || c.Type == evmc.Create2 {
 		return t.applyCreate(c)
 	}
 	return t.applyCall(c, c.Type)
 }
 
+func (t *Transition) isCheatcode(c *Contract) bool {
+	if c.Type == evmc.Create || c.Type == evmc.Create2 {
+		return false
+	}
+	for _, cheat := range t.config.Cheatcodes {
+		if cheat.CanRun(c.CodeAddress) {
+			return true
+		}
+	}
+	return false
+}
+
 func TransactionGasCost(msg *Message, isHomestead, isIstanbul bool) (uint64, error) {
 	cost := uint64(0)
 
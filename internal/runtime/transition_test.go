@@ -0,0 +1,327 @@
+package state
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCheatcode struct {
+	addr    evmc.Address
+	ctx     *CallContext
+	output  []byte
+	gasUsed uint64
+	err     error
+}
+
+func (m *mockCheatcode) CanRun(addr evmc.Address) bool {
+	return addr == m.addr
+}
+
+func (m *mockCheatcode) Run(ctx *CallContext, input []byte) ([]byte, uint64, error) {
+	m.ctx = ctx
+	return m.output, m.gasUsed, m.err
+}
+
+func TestTransition_Cheatcode(t *testing.T) {
+	cheatAddr := evmc.Address{0x1}
+	sender := evmc.Address{0x2}
+
+	cases := []struct {
+		gasUsed uint64
+		err     error
+		success bool
+		output  []byte
+		gasLeft uint64
+	}{
+		{
+			// the output is returned
+			100, nil, true, []byte{0x1, 0x2}, 900,
+		},
+		{
+			// the revert data is returned
+			100, ErrExecutionReverted, false, []byte{0x1, 0x2}, 900,
+		},
+		{
+			// other errors consume all the gas
+			100, errNotEnoughFunds, false, nil, 0,
+		},
+		{
+			// the cheatcode uses more gas than available
+			2000, nil, false, nil, 0,
+		},
+	}
+
+	for _, c := range cases {
+		cheat := &mockCheatcode{
+			addr:    cheatAddr,
+			output:  []byte{0x1, 0x2},
+			gasUsed: c.gasUsed,
+			err:     c.err,
+		}
+		transition := NewTransition(WithCheatcode(cheat))
+
+		output := transition.Apply(&Message{
+			From:     sender,
+			To:       &cheatAddr,
+			Gas:      1000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.Equal(t, c.success, output.Success)
+		assert.Equal(t, c.output, output.ReturnValue)
+		assert.Equal(t, c.gasLeft, output.GasLeft)
+
+		assert.Equal(t, sender, cheat.ctx.Caller)
+		assert.Equal(t, cheatAddr, cheat.ctx.Address)
+		assert.Equal(t, transition, cheat.ctx.Transition)
+	}
+}
+
+func TestTransition_CheatcodeReturnData(t *testing.T) {
+	cheatAddr := evmc.Address{0x1}
+	contractAddr := evmc.Address{0x3}
+
+	cheat := &mockCheatcode{
+		addr:   cheatAddr,
+		output: []byte{0x1, 0x2, 0x3},
+	}
+	transition := NewTransition(WithCheatcode(cheat))
+
+	// call the cheatcode and return its return data
+	code := "6000600060006000600073" + hex.EncodeToString(cheatAddr[:]) + "5af1503d600060003e3d6000f3"
+	buf, err := hex.DecodeString(code)
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, buf)
+
+	output := transition.Apply(&Message{
+		From:     evmc.Address{0x2},
+		To:       &contractAddr,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	})
+	assert.True(t, output.Success)
+	assert.Equal(t, []byte{0x1, 0x2, 0x3}, output.ReturnValue)
+
+	assert.Equal(t, contractAddr, cheat.ctx.Caller)
+	assert.Equal(t, 1, cheat.ctx.Depth)
+	assert.False(t, cheat.ctx.Static)
+}
+
+func TestTransition_BaseFee(t *testing.T) {
+	contractAddr := evmc.Address{0x3}
+
+	// return the base fee of the block
+	code, err := hex.DecodeString("4860005260206000f3")
+	assert.NoError(t, err)
+
+	baseFee := evmc.Hash{31: 0x7}
+
+	for _, rev := range []evmc.Revision{evmc.Istanbul, evmc.London} {
+		transition := NewTransition(WithRevision(rev))
+		transition.Context().BaseFee = baseFee
+		transition.Txn().SetCode(contractAddr, code)
+
+		output := transition.Apply(&Message{
+			From:     evmc.Address{0x2},
+			To:       &contractAddr,
+			Gas:      100000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		if rev == evmc.London {
+			assert.True(t, output.Success)
+			assert.Equal(t, baseFee[:], output.ReturnValue)
+		} else {
+			// the opcode is not available before london
+			assert.False(t, output.Success)
+		}
+	}
+}
+
+type mockHook struct {
+	caller evmc.Address
+	before []*Contract
+	after  []*Contract
+	logs   []*Log
+}
+
+func (m *mockHook) BeforeCall(t *Transition, c *Contract) {
+	m.before = append(m.before, c)
+	c.Caller = m.caller
+}
+
+func (m *mockHook) AfterCall(t *Transition, c *Contract, output []byte, err error) ([]byte, error) {
+	m.after = append(m.after, c)
+	return output, err
+}
+
+func (m *mockHook) OnLog(t *Transition, log *Log) bool {
+	m.logs = append(m.logs, log)
+	return len(log.Topics) != 0
+}
+
+func TestTransition_Hook(t *testing.T) {
+	cheatAddr := evmc.Address{0x1}
+	contractAddr := evmc.Address{0x3}
+
+	cheat := &mockCheatcode{
+		addr: cheatAddr,
+	}
+	hook := &mockHook{
+		caller: evmc.Address{0x4},
+	}
+	transition := NewTransition(WithCheatcode(cheat), WithHook(hook))
+
+	// call the cheatcode
+	code := "6000600060006000600073" + hex.EncodeToString(cheatAddr[:]) + "5af150"
+	buf, err := hex.DecodeString(code)
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, buf)
+
+	output := transition.Apply(&Message{
+		From:     evmc.Address{0x2},
+		To:       &contractAddr,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	})
+	assert.True(t, output.Success)
+
+	// the hook is not called for the cheatcode
+	assert.Len(t, hook.before, 1)
+	assert.Len(t, hook.after, 1)
+	assert.Equal(t, contractAddr, hook.before[0].Address)
+
+	// the cheatcode is called by the contract
+	assert.Equal(t, contractAddr, cheat.ctx.Caller)
+}
+
+func TestTransition_HookLog(t *testing.T) {
+	contractAddr := evmc.Address{0x3}
+
+	hook := &mockHook{}
+	transition := NewTransition(WithHook(hook))
+
+	// emit a log without topics and a log with one topic
+	code, err := hex.DecodeString("60006000a0600160006000a100")
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, code)
+
+	output := transition.Apply(&Message{
+		From:     evmc.Address{0x2},
+		To:       &contractAddr,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	})
+	assert.True(t, output.Success)
+
+	// the hook drops the log without topics
+	assert.Len(t, hook.logs, 2)
+	assert.Len(t, output.Logs, 1)
+	assert.Equal(t, evmc.Hash{31: 0x1}, output.Logs[0].Topics[0])
+}
+
+type mockMock struct {
+	addr   evmc.Address
+	output []byte
+	revert bool
+}
+
+func (m *mockMock) MockCall(c *Contract) ([]byte, bool, bool) {
+	if c.CodeAddress != m.addr {
+		return nil, false, false
+	}
+	return m.output, m.revert, true
+}
+
+func TestTransition_Mock(t *testing.T) {
+	mockAddr := evmc.Address{0x1}
+
+	for _, revert := range []bool{false, true} {
+		mock := &mockMock{
+			addr:   mockAddr,
+			output: []byte{0x1, 0x2},
+			revert: revert,
+		}
+		transition := NewTransition(WithMock(mock))
+
+		// the address has no code
+		output := transition.Apply(&Message{
+			From:     evmc.Address{0x2},
+			To:       &mockAddr,
+			Gas:      1000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		})
+		assert.Equal(t, !revert, output.Success)
+		assert.Equal(t, []byte{0x1, 0x2}, output.ReturnValue)
+		assert.Equal(t, uint64(1000), output.GasLeft)
+	}
+}
+
+type mockTracer struct {
+	reads  []evmc.Hash
+	writes []evmc.Hash
+}
+
+func (m *mockTracer) OnStorageRead(addr evmc.Address, key evmc.Hash) {
+	m.reads = append(m.reads, key)
+}
+
+func (m *mockTracer) OnStorageWrite(addr evmc.Address, key evmc.Hash) {
+	m.writes = append(m.writes, key)
+}
+
+func TestTransition_Tracer(t *testing.T) {
+	contractAddr := evmc.Address{0x3}
+
+	tracer := &mockTracer{}
+	transition := NewTransition(WithTracer(tracer))
+
+	// write the slot 2 and read the slot 3
+	code, err := hex.DecodeString("600160025560035450")
+	assert.NoError(t, err)
+	transition.Txn().SetCode(contractAddr, code)
+
+	output := transition.Apply(&Message{
+		From:     evmc.Address{0x2},
+		To:       &contractAddr,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+	})
+	assert.True(t, output.Success)
+
+	assert.Equal(t, []evmc.Hash{{31: 0x3}}, tracer.reads)
+	assert.Equal(t, []evmc.Hash{{31: 0x2}}, tracer.writes)
+}
+
+func TestTransition_ContractAddress(t *testing.T) {
+	sender := evmc.Address{0x2}
+	transition := NewTransition()
+
+	// deploy a contract with the code 0x01
+	code, err := hex.DecodeString("600160005360016000f3")
+	assert.NoError(t, err)
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		output := transition.Apply(&Message{
+			From:     sender,
+			Gas:      100000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+			Input:    code,
+		})
+		assert.True(t, output.Success)
+
+		// the address uses the nonce of the sender in the state
+		assert.Equal(t, createAddress(sender, nonce), output.ContractAddress)
+		assert.Equal(t, []byte{0x1}, transition.GetCode(output.ContractAddress))
+	}
+}
@@ -3,6 +3,7 @@ package state
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/evmc/v10/bindings/go/evmc"
 	iradix "github.com/hashicorp/go-immutable-radix"
@@ -20,9 +21,19 @@ var (
 // Txn is a reference of the state
 type Txn struct {
 	snapshot  Snapshot
-	snapshots []*iradix.Tree
+	snapshots []*txnSnapshot
 	txn       *iradix.Txn
 	rev       evmc.Revision
+
+	// nextSnapshot is the id of the next snapshot. The ids are
+	// not reused after the snapshots are discarded.
+	nextSnapshot int
+}
+
+// txnSnapshot is the state at the time of a snapshot
+type txnSnapshot struct {
+	id   int
+	tree *iradix.Tree
 }
 
 func NewTxn(snapshot Snapshot) *Txn {
@@ -34,7 +45,7 @@ func newTxn(snapshot Snapshot) *Txn {
 
 	return &Txn{
 		snapshot:  snapshot,
-		snapshots: []*iradix.Tree{},
+		snapshots: []*txnSnapshot{},
 		txn:       i.Txn(),
 	}
 }
@@ -43,20 +54,47 @@ func newTxn(snapshot Snapshot) *Txn {
 func (txn *Txn) Snapshot() int {
 	t := txn.txn.CommitOnly()
 
-	id := len(txn.snapshots)
-	txn.snapshots = append(txn.snapshots, t)
+	id := txn.nextSnapshot
+	txn.nextSnapshot++
+	txn.snapshots = append(txn.snapshots, &txnSnapshot{id: id, tree: t})
 
 	return id
 }
 
-// RevertToSnapshot reverts to a given snapshot
-func (txn *Txn) RevertToSnapshot(id int) {
-	if id > len(txn.snapshots) {
-		panic("")
+// RevertToSnapshot reverts to a given snapshot. The snapshot is kept and
+// can be used again, the snapshots taken after it are discarded.
+func (txn *Txn) RevertToSnapshot(id int) error {
+	indx, err := txn.findSnapshot(id)
+	if err != nil {
+		return err
 	}
+	txn.txn = txn.snapshots[indx].tree.Txn()
+	txn.snapshots = txn.snapshots[:indx+1]
+	return nil
+}
 
-	tree := txn.snapshots[id]
-	txn.txn = tree.Txn()
+// RestoreSnapshot reverts to a given snapshot without discarding the snapshots
+// taken after it. It is used to revert the state inside a call since the
+// snapshots of the enclosing calls are still required to revert them.
+func (txn *Txn) RestoreSnapshot(id int) error {
+	indx, err := txn.findSnapshot(id)
+	if err != nil {
+		return err
+	}
+	txn.txn = txn.snapshots[indx].tree.Txn()
+	return nil
+}
+
+// findSnapshot returns the position of the snapshot in the list
+func (txn *Txn) findSnapshot(id int) (int, error) {
+	// the snapshots are sorted by id
+	indx := sort.Search(len(txn.snapshots), func(i int) bool {
+		return txn.snapshots[i].id >= id
+	})
+	if indx == len(txn.snapshots) || txn.snapshots[indx].id != id {
+		return 0, fmt.Errorf("snapshot %d not found", id)
+	}
+	return indx, nil
 }
 
 // GetAccount returns an account
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/evmc/v10/bindings/go/evmc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxn_Snapshot(t *testing.T) {
+	txn := NewTxn(&EmptyState{})
+	addr := evmc.Address{0x1}
+
+	txn.SetBalance(addr, big.NewInt(1))
+	first := txn.Snapshot()
+
+	txn.SetBalance(addr, big.NewInt(2))
+	second := txn.Snapshot()
+
+	txn.SetBalance(addr, big.NewInt(3))
+
+	// the snapshot can be used many times
+	assert.NoError(t, txn.RevertToSnapshot(second))
+	assert.Equal(t, big.NewInt(2), txn.GetBalance(addr))
+
+	txn.SetBalance(addr, big.NewInt(4))
+	assert.NoError(t, txn.RevertToSnapshot(second))
+	assert.Equal(t, big.NewInt(2), txn.GetBalance(addr))
+
+	// the later snapshots are discarded after a revert
+	assert.NoError(t, txn.RevertToSnapshot(first))
+	assert.Equal(t, big.NewInt(1), txn.GetBalance(addr))
+	assert.Error(t, txn.RevertToSnapshot(second))
+	assert.Len(t, txn.snapshots, 1)
+
+	// the ids of the discarded snapshots are not reused
+	third := txn.Snapshot()
+	assert.NotEqual(t, second, third)
+	assert.Error(t, txn.RevertToSnapshot(second))
+
+	assert.Error(t, txn.RevertToSnapshot(-1))
+	assert.Error(t, txn.RevertToSnapshot(100))
+	assert.Equal(t, big.NewInt(1), txn.GetBalance(addr))
+}
+
+func TestTxn_RestoreSnapshot(t *testing.T) {
+	txn := NewTxn(&EmptyState{})
+	addr := evmc.Address{0x1}
+
+	txn.SetBalance(addr, big.NewInt(1))
+	first := txn.Snapshot()
+
+	txn.SetBalance(addr, big.NewInt(2))
+	second := txn.Snapshot()
+
+	txn.SetBalance(addr, big.NewInt(3))
+
+	// the later snapshots are kept
+	assert.NoError(t, txn.RestoreSnapshot(first))
+	assert.Equal(t, big.NewInt(1), txn.GetBalance(addr))
+
+	assert.NoError(t, txn.RevertToSnapshot(second))
+	assert.Equal(t, big.NewInt(2), txn.GetBalance(addr))
+
+	assert.Error(t, txn.RestoreSnapshot(100))
+}
@@ -7,8 +7,8 @@ type Compiler interface {
 }
 
 type Optimizer struct {
-	Enabled bool
-	Runs    int
+	Enabled bool `json:"enabled"`
+	Runs    int  `json:"runs"`
 }
 
 type Input struct {
@@ -24,12 +24,16 @@ type Artifact struct {
 	BinRuntime    string          `json:"bin-runtime"`
 	SrcMap        string          `json:"srcmap"`
 	SrcMapRuntime string          `json:"srcmap-runtime"`
+	StorageLayout *StorageLayout  `json:"storage-layout,omitempty"`
 }
 
 type Output struct {
 	Contracts map[string]*Artifact
 	Sources   map[string]*Source
 	Version   string
+
+	// Warnings are the diagnostics reported by a successful compilation
+	Warnings []string
 }
 
 type Source struct {
@@ -11,6 +11,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
 )
 
 type Solidity struct {
@@ -29,7 +33,7 @@ func (s *Solidity) download(version string) error {
 		return nil
 	}
 	// download the compiler since no one is doing it already
-	fmt.Printf("Downloading solidity %s...\n", version)
+	fmt.Fprintf(os.Stderr, "Downloading solidity %s...\n", version)
 	if err := downloadSolidity(version, s.Dst); err != nil {
 		return err
 	}
@@ -38,7 +42,6 @@ func (s *Solidity) download(version string) error {
 
 func (s *Solidity) Compile(input *Input) (*Output, error) {
 	version := input.Version
-	files := input.Files
 
 	if err := s.download(version); err != nil {
 		return nil, err
@@ -46,22 +49,8 @@ func (s *Solidity) Compile(input *Input) (*Output, error) {
 
 	path := s.Path(version)
 
-	args := []string{
-		"--combined-json",
-		"bin,bin-runtime,srcmap-runtime,abi,srcmap,ast",
-	}
-	if len(input.Remappings) != 0 {
-		for k, v := range input.Remappings {
-			args = append(args, k+"="+v)
-		}
-	}
-
-	if len(files) != 0 {
-		args = append(args, files...)
-	}
-
 	var stdout, stderr bytes.Buffer
-	cmd := exec.Command(path, args...)
+	cmd := exec.Command(path, Args(input)...)
 
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -73,9 +62,52 @@ func (s *Solidity) Compile(input *Input) (*Output, error) {
 	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
 		return nil, err
 	}
+	output.Warnings = parseWarnings(stderr.String())
 	return output, nil
 }
 
+// parseWarnings splits the messages that solc writes in stderr
+// after a successful compilation
+func parseWarnings(str string) []string {
+	warnings := []string{}
+	for _, msg := range strings.Split(str, "\n\n") {
+		if msg = strings.TrimSpace(msg); msg != "" {
+			warnings = append(warnings, msg)
+		}
+	}
+	return warnings
+}
+
+// storageLayoutVersion is the first version of the compiler that
+// reports the storage layout in the combined json output
+var storageLayoutVersion = version.Must(version.NewVersion("0.5.13"))
+
+// Args returns the arguments of the solc compiler for the input
+func Args(input *Input) []string {
+	combined := "bin,bin-runtime,srcmap-runtime,abi,srcmap,ast"
+	if v, err := version.NewVersion(input.Version); err == nil && v.GreaterThanOrEqual(storageLayoutVersion) {
+		combined += ",storage-layout"
+	}
+	args := []string{
+		"--combined-json",
+		combined,
+	}
+	if len(input.Remappings) != 0 {
+		remappings := []string{}
+		for k, v := range input.Remappings {
+			remappings = append(remappings, k+"="+v)
+		}
+		// sort the remappings to have a deterministic input
+		sort.Strings(remappings)
+		args = append(args, remappings...)
+	}
+
+	if len(input.Files) != 0 {
+		args = append(args, input.Files...)
+	}
+	return args
+}
+
 func (s *Solidity) Path(version string) string {
 	return filepath.Join(s.Dst, "solidity-"+version)
 }
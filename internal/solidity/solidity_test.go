@@ -1,8 +1,10 @@
 package solidity
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +18,27 @@ func TestDownloader(t *testing.T) {
 
 	assert.NoError(t, downloadSolidity("0.8.0", tmpDir))
 }
+
+func TestArgs_StorageLayout(t *testing.T) {
+	args := Args(&Input{Version: "0.5.12"})
+	assert.NotContains(t, args[1], "storage-layout")
+
+	args = Args(&Input{Version: "0.8.4"})
+	assert.Contains(t, args[1], "storage-layout")
+}
+
+func TestStorageLayout_UnmarshalJSON(t *testing.T) {
+	layout := `{"storage":[{"astId":1,"contract":"contracts/A.sol:A","label":"a","offset":0,"slot":"0","type":"t_uint256"}],"types":{"t_uint256":{"encoding":"inplace","label":"uint256","numberOfBytes":"32"}}}`
+	escaped, err := json.Marshal(layout)
+	assert.NoError(t, err)
+
+	// before 0.8.0 the layout is a string, which may use any json escape
+	str := strings.Replace(string(escaped), "contracts/A.sol", `contracts\/A.sol`, 1)
+
+	for _, data := range []string{layout, str} {
+		var res StorageLayout
+		assert.NoError(t, json.Unmarshal([]byte(data), &res))
+		assert.Equal(t, "contracts/A.sol:A", res.Storage[0].Contract)
+		assert.Equal(t, "uint256", res.Types["t_uint256"].Label)
+	}
+}
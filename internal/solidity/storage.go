@@ -0,0 +1,45 @@
+package solidity
+
+import "encoding/json"
+
+// StorageLayout is the storage layout of a contract reported by
+// the compiler (available since 0.5.13)
+type StorageLayout struct {
+	Storage []*StorageEntry         `json:"storage"`
+	Types   map[string]*StorageType `json:"types"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Before 0.8.0
+// the combined json output encodes the layout as a string.
+func (s *StorageLayout) UnmarshalJSON(data []byte) error {
+	if len(data) != 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		data = []byte(str)
+	}
+	type layout StorageLayout
+	return json.Unmarshal(data, (*layout)(s))
+}
+
+// StorageEntry is a state variable (or a struct member) in storage
+type StorageEntry struct {
+	AstID    int    `json:"astId"`
+	Contract string `json:"contract"`
+	Label    string `json:"label"`
+	Offset   int    `json:"offset"`
+	Slot     string `json:"slot"`
+	Type     string `json:"type"`
+}
+
+// StorageType is the description of a type in the storage layout
+type StorageType struct {
+	Encoding      string          `json:"encoding"`
+	Label         string          `json:"label"`
+	NumberOfBytes string          `json:"numberOfBytes"`
+	Key           string          `json:"key,omitempty"`
+	Value         string          `json:"value,omitempty"`
+	Base          string          `json:"base,omitempty"`
+	Members       []*StorageEntry `json:"members,omitempty"`
+}
@@ -0,0 +1,430 @@
+// SPDX-License-Identifier: MIT
+pragma solidity >=0.8.0 <0.9.0;
+
+/// @notice Base contract for the tests with assertions. A failed assertion is reported
+/// to greenhouse with its values and reverts the test. The assertions with two values
+/// (i.e. assertEq(a, b)) take the actual value first and the expected value second.
+abstract contract Test {
+	address constant ASSERTION_ADDRESS = address(0x677265656e686f7573652E617373657274696f6E);
+
+	function _fail(bytes memory payload) private view {
+		uint256 payloadLength = payload.length;
+		address assertionAddress = ASSERTION_ADDRESS;
+		assembly {
+			let payloadStart := add(payload, 32)
+			let r := staticcall(gas(), assertionAddress, payloadStart, payloadLength, 0, 0)
+		}
+		revert("assertion failed");
+	}
+
+	function _delta(uint256 a, uint256 b) private pure returns (uint256) {
+		return a > b ? a - b : b - a;
+	}
+
+	function _delta(int256 a, int256 b) private pure returns (uint256) {
+		// values with the same sign cannot overflow
+		if ((a >= 0 && b >= 0) || (a < 0 && b < 0)) {
+			return a > b ? uint256(a - b) : uint256(b - a);
+		}
+		return _abs(a) + _abs(b);
+	}
+
+	function _abs(int256 a) private pure returns (uint256) {
+		if (a == type(int256).min) {
+			return uint256(type(int256).max) + 1;
+		}
+		return uint256(a >= 0 ? a : -a);
+	}
+
+	function fail(string memory err) internal view {
+		_fail(abi.encodeWithSignature("fail(string)", err));
+	}
+
+	function assertTrue(bool condition) internal view {
+		if (!condition) {
+			_fail(abi.encodeWithSignature("assertTrue(bool)", condition));
+		}
+	}
+
+	function assertTrue(bool condition, string memory err) internal view {
+		if (!condition) {
+			_fail(abi.encodeWithSignature("assertTrue(bool,string)", condition, err));
+		}
+	}
+
+	function assertFalse(bool condition) internal view {
+		if (condition) {
+			_fail(abi.encodeWithSignature("assertFalse(bool)", condition));
+		}
+	}
+
+	function assertFalse(bool condition, string memory err) internal view {
+		if (condition) {
+			_fail(abi.encodeWithSignature("assertFalse(bool,string)", condition, err));
+		}
+	}
+
+	function assertEq(bool a, bool b) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(bool,bool)", a, b));
+		}
+	}
+
+	function assertEq(bool a, bool b, string memory err) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(bool,bool,string)", a, b, err));
+		}
+	}
+
+	function assertEq(uint256 a, uint256 b) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertEq(uint256 a, uint256 b, string memory err) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertEq(int256 a, int256 b) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(int256,int256)", a, b));
+		}
+	}
+
+	function assertEq(int256 a, int256 b, string memory err) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertEq(address a, address b) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(address,address)", a, b));
+		}
+	}
+
+	function assertEq(address a, address b, string memory err) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(address,address,string)", a, b, err));
+		}
+	}
+
+	function assertEq(bytes32 a, bytes32 b) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(bytes32,bytes32)", a, b));
+		}
+	}
+
+	function assertEq(bytes32 a, bytes32 b, string memory err) internal view {
+		if (a != b) {
+			_fail(abi.encodeWithSignature("assertEq(bytes32,bytes32,string)", a, b, err));
+		}
+	}
+
+	function assertEq(string memory a, string memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(string,string)", a, b));
+		}
+	}
+
+	function assertEq(string memory a, string memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(string,string,string)", a, b, err));
+		}
+	}
+
+	function assertEq(bytes memory a, bytes memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(bytes,bytes)", a, b));
+		}
+	}
+
+	function assertEq(bytes memory a, bytes memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(bytes,bytes,string)", a, b, err));
+		}
+	}
+
+	function assertEq(uint256[] memory a, uint256[] memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(uint256[],uint256[])", a, b));
+		}
+	}
+
+	function assertEq(uint256[] memory a, uint256[] memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(uint256[],uint256[],string)", a, b, err));
+		}
+	}
+
+	function assertEq(int256[] memory a, int256[] memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(int256[],int256[])", a, b));
+		}
+	}
+
+	function assertEq(int256[] memory a, int256[] memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(int256[],int256[],string)", a, b, err));
+		}
+	}
+
+	function assertEq(address[] memory a, address[] memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(address[],address[])", a, b));
+		}
+	}
+
+	function assertEq(address[] memory a, address[] memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(address[],address[],string)", a, b, err));
+		}
+	}
+
+	function assertEq(bytes32[] memory a, bytes32[] memory b) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(bytes32[],bytes32[])", a, b));
+		}
+	}
+
+	function assertEq(bytes32[] memory a, bytes32[] memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) != keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertEq(bytes32[],bytes32[],string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(bool a, bool b) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(bool,bool)", a, b));
+		}
+	}
+
+	function assertNotEq(bool a, bool b, string memory err) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(bool,bool,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(uint256 a, uint256 b) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertNotEq(uint256 a, uint256 b, string memory err) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(int256 a, int256 b) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(int256,int256)", a, b));
+		}
+	}
+
+	function assertNotEq(int256 a, int256 b, string memory err) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(address a, address b) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(address,address)", a, b));
+		}
+	}
+
+	function assertNotEq(address a, address b, string memory err) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(address,address,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(bytes32 a, bytes32 b) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(bytes32,bytes32)", a, b));
+		}
+	}
+
+	function assertNotEq(bytes32 a, bytes32 b, string memory err) internal view {
+		if (a == b) {
+			_fail(abi.encodeWithSignature("assertNotEq(bytes32,bytes32,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(string memory a, string memory b) internal view {
+		if (keccak256(abi.encode(a)) == keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertNotEq(string,string)", a, b));
+		}
+	}
+
+	function assertNotEq(string memory a, string memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) == keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertNotEq(string,string,string)", a, b, err));
+		}
+	}
+
+	function assertNotEq(bytes memory a, bytes memory b) internal view {
+		if (keccak256(abi.encode(a)) == keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertNotEq(bytes,bytes)", a, b));
+		}
+	}
+
+	function assertNotEq(bytes memory a, bytes memory b, string memory err) internal view {
+		if (keccak256(abi.encode(a)) == keccak256(abi.encode(b))) {
+			_fail(abi.encodeWithSignature("assertNotEq(bytes,bytes,string)", a, b, err));
+		}
+	}
+
+	function assertLt(uint256 a, uint256 b) internal view {
+		if (a >= b) {
+			_fail(abi.encodeWithSignature("assertLt(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertLt(uint256 a, uint256 b, string memory err) internal view {
+		if (a >= b) {
+			_fail(abi.encodeWithSignature("assertLt(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertLt(int256 a, int256 b) internal view {
+		if (a >= b) {
+			_fail(abi.encodeWithSignature("assertLt(int256,int256)", a, b));
+		}
+	}
+
+	function assertLt(int256 a, int256 b, string memory err) internal view {
+		if (a >= b) {
+			_fail(abi.encodeWithSignature("assertLt(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertGt(uint256 a, uint256 b) internal view {
+		if (a <= b) {
+			_fail(abi.encodeWithSignature("assertGt(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertGt(uint256 a, uint256 b, string memory err) internal view {
+		if (a <= b) {
+			_fail(abi.encodeWithSignature("assertGt(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertGt(int256 a, int256 b) internal view {
+		if (a <= b) {
+			_fail(abi.encodeWithSignature("assertGt(int256,int256)", a, b));
+		}
+	}
+
+	function assertGt(int256 a, int256 b, string memory err) internal view {
+		if (a <= b) {
+			_fail(abi.encodeWithSignature("assertGt(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertLe(uint256 a, uint256 b) internal view {
+		if (a > b) {
+			_fail(abi.encodeWithSignature("assertLe(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertLe(uint256 a, uint256 b, string memory err) internal view {
+		if (a > b) {
+			_fail(abi.encodeWithSignature("assertLe(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertLe(int256 a, int256 b) internal view {
+		if (a > b) {
+			_fail(abi.encodeWithSignature("assertLe(int256,int256)", a, b));
+		}
+	}
+
+	function assertLe(int256 a, int256 b, string memory err) internal view {
+		if (a > b) {
+			_fail(abi.encodeWithSignature("assertLe(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertGe(uint256 a, uint256 b) internal view {
+		if (a < b) {
+			_fail(abi.encodeWithSignature("assertGe(uint256,uint256)", a, b));
+		}
+	}
+
+	function assertGe(uint256 a, uint256 b, string memory err) internal view {
+		if (a < b) {
+			_fail(abi.encodeWithSignature("assertGe(uint256,uint256,string)", a, b, err));
+		}
+	}
+
+	function assertGe(int256 a, int256 b) internal view {
+		if (a < b) {
+			_fail(abi.encodeWithSignature("assertGe(int256,int256)", a, b));
+		}
+	}
+
+	function assertGe(int256 a, int256 b, string memory err) internal view {
+		if (a < b) {
+			_fail(abi.encodeWithSignature("assertGe(int256,int256,string)", a, b, err));
+		}
+	}
+
+	function assertApproxEqAbs(uint256 a, uint256 b, uint256 maxDelta) internal view {
+		if (_delta(a, b) > maxDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqAbs(uint256,uint256,uint256)", a, b, maxDelta));
+		}
+	}
+
+	function assertApproxEqAbs(uint256 a, uint256 b, uint256 maxDelta, string memory err) internal view {
+		if (_delta(a, b) > maxDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqAbs(uint256,uint256,uint256,string)", a, b, maxDelta, err));
+		}
+	}
+
+	function assertApproxEqAbs(int256 a, int256 b, uint256 maxDelta) internal view {
+		if (_delta(a, b) > maxDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqAbs(int256,int256,uint256)", a, b, maxDelta));
+		}
+	}
+
+	function assertApproxEqAbs(int256 a, int256 b, uint256 maxDelta, string memory err) internal view {
+		if (_delta(a, b) > maxDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqAbs(int256,int256,uint256,string)", a, b, maxDelta, err));
+		}
+	}
+
+	function assertApproxEqRel(uint256 a, uint256 b, uint256 maxPercentDelta) internal view {
+		// maxPercentDelta is a fixed point value where 1e18 is 100%
+		if (b == 0 ? a != b : _delta(a, b) * 1e18 / b > maxPercentDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqRel(uint256,uint256,uint256)", a, b, maxPercentDelta));
+		}
+	}
+
+	function assertApproxEqRel(uint256 a, uint256 b, uint256 maxPercentDelta, string memory err) internal view {
+		// maxPercentDelta is a fixed point value where 1e18 is 100%
+		if (b == 0 ? a != b : _delta(a, b) * 1e18 / b > maxPercentDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqRel(uint256,uint256,uint256,string)", a, b, maxPercentDelta, err));
+		}
+	}
+
+	function assertApproxEqRel(int256 a, int256 b, uint256 maxPercentDelta) internal view {
+		// maxPercentDelta is a fixed point value where 1e18 is 100%
+		if (b == 0 ? a != b : _delta(a, b) * 1e18 / _abs(b) > maxPercentDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqRel(int256,int256,uint256)", a, b, maxPercentDelta));
+		}
+	}
+
+	function assertApproxEqRel(int256 a, int256 b, uint256 maxPercentDelta, string memory err) internal view {
+		// maxPercentDelta is a fixed point value where 1e18 is 100%
+		if (b == 0 ? a != b : _delta(a, b) * 1e18 / _abs(b) > maxPercentDelta) {
+			_fail(abi.encodeWithSignature("assertApproxEqRel(int256,int256,uint256,string)", a, b, maxPercentDelta, err));
+		}
+	}
+}
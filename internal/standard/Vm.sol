@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT
+pragma solidity >=0.8.0 <0.9.0;
+
+/// @notice Cheatcodes of the greenhouse test runner. The calls to the vm
+/// address are run by greenhouse and change the environment of the tests.
+interface Vm {
+	struct Log {
+		bytes32[] topics;
+		bytes data;
+		address emitter;
+	}
+
+	// Sets block.timestamp
+	function warp(uint256 timestamp) external;
+
+	// Sets block.number
+	function roll(uint256 number) external;
+
+	// Sets block.basefee
+	function fee(uint256 baseFee) external;
+
+	// Sets block.chainid
+	function chainId(uint256 id) external;
+
+	// Sets block.coinbase
+	function coinbase(address coinbase) external;
+
+	// Sets block.difficulty
+	function difficulty(uint256 difficulty) external;
+
+	// Sets block.prevrandao, which replaces block.difficulty after the merge
+	function prevrandao(bytes32 prevrandao) external;
+
+	// Sets tx.gasprice for the rest of the transaction
+	function txGasPrice(uint256 gasPrice) external;
+
+	// Sets msg.sender for the next call
+	function prank(address sender) external;
+
+	// Sets msg.sender and tx.origin for the next call
+	function prank(address sender, address origin) external;
+
+	// Sets msg.sender for the next calls until stopPrank is called
+	function startPrank(address sender) external;
+
+	// Sets msg.sender and tx.origin for the next calls until stopPrank is called
+	function startPrank(address sender, address origin) external;
+
+	// Stops the prank started with startPrank
+	function stopPrank() external;
+
+	// Sets the balance of an account
+	function deal(address account, uint256 balance) external;
+
+	// Stores a value in a storage slot of an account
+	function store(address account, bytes32 slot, bytes32 value) external;
+
+	// Loads the value of a storage slot of an account
+	function load(address account, bytes32 slot) external view returns (bytes32 value);
+
+	// Sets the code of an account
+	function etch(address account, bytes calldata code) external;
+
+	// Sets the nonce of an account
+	function setNonce(address account, uint64 nonce) external;
+
+	// Returns the nonce of an account
+	function getNonce(address account) external view returns (uint64 nonce);
+
+	// Returns the creation code of a contract of the project by name (i.e. Token or Token.sol:Token)
+	function getCode(string calldata name) external view returns (bytes memory code);
+
+	// Returns the deployed code of a contract of the project by name (i.e. Token or Token.sol:Token)
+	function getDeployedCode(string calldata name) external view returns (bytes memory code);
+
+	// Expects the next call to revert
+	function expectRevert() external;
+
+	// Expects the next call to revert with the selector of an error
+	function expectRevert(bytes4 selector) external;
+
+	// Expects the next call to revert with the data, or with a reason for revert(string)
+	function expectRevert(bytes calldata data) external;
+
+	// Expects the next call to emit the next event emitted by the test. The topic 0
+	// is always checked, the flags select which other topics and the data are checked.
+	function expectEmit(bool checkTopic1, bool checkTopic2, bool checkTopic3, bool checkData) external;
+
+	// Expects the next call to emit the next event emitted by the test from the emitter
+	function expectEmit(bool checkTopic1, bool checkTopic2, bool checkTopic3, bool checkData, address emitter) external;
+
+	// Expects a call to the callee with calldata that starts with the data during the next call,
+	// including the calls it makes
+	function expectCall(address callee, bytes calldata data) external;
+
+	// Expects a call to the callee with the value and calldata that starts with the data during the next call
+	function expectCall(address callee, uint256 value, bytes calldata data) external;
+
+	// Expects count calls to the callee with calldata that starts with the data during the next call
+	function expectCall(address callee, bytes calldata data, uint64 count) external;
+
+	// Expects count calls to the callee with the value and calldata that starts with the data during the next call
+	function expectCall(address callee, uint256 value, bytes calldata data, uint64 count) external;
+
+	// Returns the data for the calls to the callee with calldata that starts with the data,
+	// without running the code of the callee
+	function mockCall(address callee, bytes calldata data, bytes calldata returnData) external;
+
+	// Reverts with the data for the calls to the callee with calldata that starts with the data
+	function mockCallRevert(address callee, bytes calldata data, bytes calldata revertData) external;
+
+	// Removes all the mocked calls
+	function clearMockedCalls() external;
+
+	// Takes a snapshot of the state and the block environment and returns its id
+	function snapshot() external returns (uint256 id);
+
+	// Reverts the state and the block environment to a snapshot. The snapshot
+	// can be used again and the snapshots taken after it are discarded.
+	// Returns false if the snapshot does not exist.
+	function revertTo(uint256 id) external returns (bool success);
+
+	// Starts recording the storage slots read and written by the accounts
+	function record() external;
+
+	// Returns the storage slots read and written by the account since record was called
+	function accesses(address account) external returns (bytes32[] memory reads, bytes32[] memory writes);
+
+	// Starts recording the emitted logs
+	function recordLogs() external;
+
+	// Returns the logs emitted since recordLogs or the previous call to getRecordedLogs
+	function getRecordedLogs() external returns (Log[] memory logs);
+
+	// Reads an environment variable listed in permissions.env of greenhouse.hcl
+	// as a decimal or 0x prefixed hex number
+	function envUint(string calldata name) external view returns (uint256 value);
+
+	// Reads an environment variable listed in permissions.env of greenhouse.hcl as an address
+	function envAddress(string calldata name) external view returns (address value);
+
+	// Reads an environment variable listed in permissions.env of greenhouse.hcl
+	function envString(string calldata name) external view returns (string memory value);
+
+	// Reads an environment variable as a number or returns the default value if it is not set
+	function envOr(string calldata name, uint256 defaultValue) external view returns (uint256 value);
+
+	// Reads an environment variable as an address or returns the default value if it is not set
+	function envOr(string calldata name, address defaultValue) external view returns (address value);
+
+	// Reads an environment variable or returns the default value if it is not set
+	function envOr(string calldata name, string calldata defaultValue) external view returns (string memory value);
+
+	// Reads a file of the project listed in permissions.read of greenhouse.hcl
+	function readFile(string calldata path) external view returns (string memory data);
+
+	// Reads the next line of a file of the project, returns an empty string at the end of the file
+	function readLine(string calldata path) external view returns (string memory line);
+
+	// Writes a file of the project listed in permissions.write of greenhouse.hcl
+	function writeFile(string calldata path, string calldata data) external;
+
+	// Runs a local command if permissions.ffi is set in greenhouse.hcl and returns its output.
+	// An output in hex with the 0x prefix is decoded.
+	function ffi(string[] calldata command) external returns (bytes memory output);
+
+	// Signs the digest with the private key. The signature is accepted by ecrecover.
+	function sign(uint256 privateKey, bytes32 digest) external pure returns (uint8 v, bytes32 r, bytes32 s);
+
+	// Returns the address of the private key
+	function addr(uint256 privateKey) external pure returns (address account);
+
+	// Derives the private key at m/44'/60'/0'/0/{index} from the mnemonic
+	function deriveKey(string calldata mnemonic, uint32 index) external pure returns (uint256 privateKey);
+
+	// Returns the EIP-712 domain separator with the name, version, chain id and verifying contract
+	function domainSeparator(string calldata name, string calldata version, uint256 chainId, address verifyingContract) external pure returns (bytes32 separator);
+
+	// Returns the EIP-712 digest of the struct hash to sign (keccak256("\x19\x01" ++ domainSeparator ++ structHash))
+	function hashTypedData(bytes32 domainSeparator, bytes32 structHash) external pure returns (bytes32 digest);
+}
+
+Vm constant vm = Vm(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
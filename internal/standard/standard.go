@@ -5,6 +5,14 @@ import _ "embed"
 //go:embed console.sol
 var console string
 
+//go:embed Test.sol
+var testContract string
+
+//go:embed Vm.sol
+var vmContract string
+
 var SystemContracts = map[string]string{
 	"greenhouse/console.sol": console,
+	"greenhouse/Test.sol":    testContract,
+	"greenhouse/Vm.sol":      vmContract,
 }
@@ -0,0 +1,48 @@
+package standard
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// AssertionAddress is the address that receives the failed assertions of greenhouse/Test.sol
+var AssertionAddress = ethgo.HexToAddress("0x677265656e686f7573652E617373657274696f6E")
+
+// Assertion is a failed assertion reported by greenhouse/Test.sol
+type Assertion struct {
+	// Name is the name of the assertion (i.e. assertEq)
+	Name string
+
+	// Signature is the signature of the assertion (i.e. assertEq(uint256,uint256))
+	Signature string
+
+	// Type is the type of the arguments of the assertion
+	Type *abi.Type
+}
+
+// AssertionCases are the assertions of greenhouse/Test.sol indexed by selector
+var AssertionCases = map[string]*Assertion{}
+
+func init() {
+	rxp := regexp.MustCompile(`abi.encodeWithSignature\("((\w+)\((.*?)\))"`)
+	matches := rxp.FindAllStringSubmatch(testContract, -1)
+
+	for _, match := range matches {
+		signature, name, args := match[1], match[2], match[3]
+
+		typ, err := abi.NewType("tuple(" + args + ")")
+		if err != nil {
+			panic(fmt.Errorf("BUG: Failed to parse %s", signature))
+		}
+		sig := ethgo.Keccak256([]byte(signature))[:4]
+		AssertionCases[hex.EncodeToString(sig)] = &Assertion{
+			Name:      name,
+			Signature: signature,
+			Type:      typ,
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package standard
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// VMAddress is the address of the cheatcodes of greenhouse/Vm.sol
+var VMAddress = ethgo.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+
+// VMMethods are the cheatcodes of greenhouse/Vm.sol indexed by selector
+var VMMethods = map[string]*abi.Method{}
+
+func init() {
+	// the structs are declared with their tuple type in the methods
+	structs := map[*regexp.Regexp]string{}
+	for _, match := range regexp.MustCompile(`struct (\w+) \{([^}]*)\}`).FindAllStringSubmatch(vmContract, -1) {
+		fields := []string{}
+		for _, field := range strings.Split(match[2], ";") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+		structs[regexp.MustCompile(`\b`+match[1]+`\b`)] = "tuple(" + strings.Join(fields, ", ") + ")"
+	}
+
+	rxp := regexp.MustCompile(`function (\w+\(.*?\).*?);`)
+	matches := rxp.FindAllStringSubmatch(vmContract, -1)
+
+	for _, match := range matches {
+		// the abi parser does not know about data locations
+		decl := strings.NewReplacer(" calldata", "", " memory", "").Replace(match[1])
+		for name, tuple := range structs {
+			decl = name.ReplaceAllString(decl, tuple)
+		}
+
+		method, err := abi.NewMethod(decl)
+		if err != nil {
+			panic(fmt.Errorf("BUG: Failed to parse %s", match[1]))
+		}
+		VMMethods[hex.EncodeToString(method.ID())] = method
+	}
+}
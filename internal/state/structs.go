@@ -81,6 +81,15 @@ type Contract struct {
 
 	// SrcMapRuntime is the source map object for the deployed contract
 	SrcMapRuntime string `json:"srcmap-runtime"`
+
+	// StorageLayout is the layout of the state variables in storage
+	StorageLayout *solidity.StorageLayout `json:"storage-layout,omitempty"`
+
+	// Compiler is the version of the compiler (i.e. 0.8.4+commit.c7e474f2)
+	Compiler string `json:"compiler,omitempty"`
+
+	// Optimizer is the optimizer setting used to compile the contract
+	Optimizer *solidity.Optimizer `json:"optimizer,omitempty"`
 }
 
 func (c *Contract) ABI() *abi.ABI {